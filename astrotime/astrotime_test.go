@@ -0,0 +1,165 @@
+package astrotime
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Spectrafy/gosolar/internal/angle"
+)
+
+func TestJd(t *testing.T) {
+	for _, tt := range []struct {
+		t  time.Time
+		jd float64
+	}{
+		{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), 2451545.0},
+		{time.Date(1987, 6, 19, 12, 0, 0, 0, time.UTC), 2446966.0},
+		{time.Date(1858, 11, 17, 0, 0, 0, 0, time.UTC), MJD_ZERO},
+		{time.Date(2003, 10, 17, 19, 30, 30, 0, time.UTC), 2452930.3128472222},
+	} {
+		if got := Jd(tt.t); math.Abs(got-tt.jd) > 1e-9 {
+			t.Errorf("Jd(%v) = %.9f, want %.9f", tt.t, got, tt.jd)
+		}
+		if got := Jd_time(tt.jd); got.Sub(tt.t).Abs() > 50*time.Microsecond { // float64 resolution of a Julian Date
+			t.Errorf("Jd_time(%.9f) = %v, want %v", tt.jd, got, tt.t)
+		}
+	}
+
+	for _, tt := range []struct {
+		t   time.Time
+		mjd float64
+	}{
+		{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 40587},
+		{time.Date(1858, 11, 17, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 57754},
+		{time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC), 57754.5},
+	} {
+		if got := Mjd(tt.t); got != tt.mjd {
+			t.Errorf("Mjd(%v) = %v, want %v", tt.t, got, tt.mjd)
+		}
+		if got := Mjd_time(tt.mjd); !got.Equal(tt.t) {
+			t.Errorf("Mjd_time(%v) = %v, want %v", tt.mjd, got, tt.t)
+		}
+	}
+
+	// the same date in the three calendars: 1582 October 15 (Gregorian) follows October 4 (Julian)
+	for _, tt := range []struct {
+		calendar int
+		day      int
+		jd       float64
+	}{
+		{CALENDAR_GREGORIAN_SWITCH, 4, 2299159.5},
+		{CALENDAR_GREGORIAN_SWITCH, 15, 2299160.5},
+		{CALENDAR_GREGORIAN, 15, 2299160.5},
+		{CALENDAR_JULIAN, 5, 2299160.5},
+	} {
+		if got := Julian_day(1582, 10, tt.day, 0, 0, 0, 0, tt.calendar); got != tt.jd {
+			t.Errorf("calendar %d: Julian_day(1582-10-%02d) = %.1f, want %.1f", tt.calendar, tt.day, got, tt.jd)
+		}
+	}
+	if got := Julian_day(2003, 10, 17, 12, 30, 30, -7, CALENDAR_GREGORIAN); math.Abs(got-2452930.3128472222) > 1e-9 {
+		t.Errorf("Julian_day(2003-10-17 12:30:30 -7h) = %.9f", got)
+	}
+	if got := J2000_centuries(Centuries_jd(0.25)); math.Abs(got-0.25) > 1e-15 {
+		t.Errorf("J2000_centuries(Centuries_jd(0.25)) = %v", got)
+	}
+}
+
+func TestSidereal(t *testing.T) {
+	// Meeus, Astronomical Algorithms, examples 12.a and 12.b
+	jd := Jd(time.Date(1987, 4, 10, 0, 0, 0, 0, time.UTC))
+	if got, want := Gmst(jd), 15*(13+10/60.0+46.3668/3600); math.Abs(got-want) > 1e-6 {
+		t.Errorf("Gmst = %.7f, want %.7f", got, want)
+	}
+	// nutation in longitude -3.788" and true obliquity 23 26' 36.85"
+	del_psi, epsilon := -3.788/3600, 23+26/60.0+36.85/3600
+	if got, want := Gast(jd, del_psi, epsilon), 15*(13+10/60.0+46.1351/3600); math.Abs(got-want) > 1e-5 {
+		t.Errorf("Gast = %.7f, want %.7f", got, want)
+	}
+
+	jd = Jd(time.Date(1987, 4, 10, 19, 21, 0, 0, time.UTC))
+	if got, want := Gmst(jd), 128.7378734; math.Abs(got-want) > 1e-6 {
+		t.Errorf("Gmst = %.7f, want %.7f", got, want)
+	}
+	if got, want := Lst(jd, del_psi, epsilon, -77.0), angle.Limit_degrees(Gast(jd, del_psi, epsilon)-77.0); got != want {
+		t.Errorf("Lst = %.7f, want %.7f", got, want)
+	}
+}
+
+func TestBuiltin_leap_steps(t *testing.T) {
+	steps := Builtin_leap_steps()
+	if len(steps) != 28 || steps[0].Tai_utc != 10 || steps[27].Tai_utc != 37 {
+		t.Fatalf("%d steps from %v to %v", len(steps), steps[0], steps[len(steps)-1])
+	}
+
+	for _, s := range steps {
+		before, _ := Builtin_leap_seconds.TAI_UTC(s.Utc.Add(-time.Second))
+		if got, err := Builtin_leap_seconds.TAI_UTC(s.Utc); err != nil || got != s.Tai_utc {
+			t.Errorf("TAI-UTC at %v = %v, %v, want %v", s.Utc, got, err, s.Tai_utc)
+		}
+		if s.Tai_utc > 10 && before != s.Tai_utc-1 {
+			t.Errorf("TAI-UTC before %v = %v, want %v", s.Utc, before, s.Tai_utc-1)
+		}
+	}
+}
+
+type fixed_leap float64
+
+func (f fixed_leap) TAI_UTC(utc time.Time) (float64, error) {
+	return float64(f), nil
+}
+
+type fixed_dut1 float64
+
+func (f fixed_dut1) DUT1(utc time.Time) (float64, error) {
+	return float64(f), nil
+}
+
+func TestTime_scales(t *testing.T) {
+	var s Time_scales
+	utc := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		scale Scale
+		want  time.Time
+	}{
+		{UTC, utc},
+		{UT1, utc},
+		{TAI, utc.Add(37 * time.Second)},
+		{TT, utc.Add(69184 * time.Millisecond)},
+		{GPS, utc.Add(18 * time.Second)},
+	} {
+		got, err := s.Convert(utc, UTC, tt.scale)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("%v reading of %v = %v, %v; want %v", tt.scale, utc, got, err, tt.want)
+		}
+
+		back, err := s.Convert(got, tt.scale, UTC)
+		if err != nil || !back.Equal(utc) {
+			t.Errorf("UTC reading of %v %v = %v, %v; want %v", tt.scale, got, back, err, utc)
+		}
+	}
+
+	// the second before the leap second inserted at the end of 2016
+	before := utc.Add(-time.Second)
+	if got, _ := s.Convert(before, UTC, TAI); !got.Equal(utc.Add(35 * time.Second)) {
+		t.Errorf("TAI reading of %v = %v", before, got)
+	}
+	if got, _ := s.Convert(utc.Add(35*time.Second), TAI, UTC); !got.Equal(before) {
+		t.Errorf("UTC reading of TAI %v = %v, want %v", utc.Add(35*time.Second), got, before)
+	}
+
+	if _, err := s.Convert(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), UTC, TT); !errors.Is(err, ErrNoLeapSeconds) {
+		t.Errorf("1970 conversion error %v, want ErrNoLeapSeconds", err)
+	}
+
+	injected := Time_scales{Leap: fixed_leap(40), Dut1: fixed_dut1(-0.25)}
+	if got, _ := injected.Convert(utc, UTC, TT); !got.Equal(utc.Add(72184 * time.Millisecond)) {
+		t.Errorf("TT reading with injected leap seconds = %v", got)
+	}
+	if jd, _ := injected.Jd(utc, UT1); math.Abs(jd-(Jd(utc)-0.25/86400.0)) > 1e-10 {
+		t.Errorf("UT1 Julian Date with injected DUT1 = %.10f", jd)
+	}
+}
@@ -0,0 +1,104 @@
+// Package astrotime converts between time.Time and the day counts used in astronomy (Julian
+// Date, Modified Julian Date, Julian centuries from J2000.0), computes Greenwich mean and
+// apparent and local sidereal time, and converts clock readings between the time scales UTC,
+// UT1, TAI, TT and GPS.
+//
+// A time.Time has no leap seconds, so it is taken as a clock reading of the scale it is
+// labelled with: the UTC reading 2017-01-01 00:00:00 is the TT reading 2017-01-01 00:01:09.184.
+// Julian dates are those of the reading, e.g. Jd of a TT reading is the Julian ephemeris day.
+// Dates are in the proleptic Gregorian calendar of package time; Julian_day also takes the
+// Julian calendar and the switch between the two of NREL's SPA.
+//
+// gosolar and iers take their Julian dates, sidereal time and built-in leap seconds from this
+// package, which imports neither.
+package astrotime
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	J2000         = 2451545.0 // Julian Date of the epoch J2000.0 (2000-01-01 12:00 TT)
+	MJD_ZERO      = 2400000.5 // Julian Date of Modified Julian Date 0 (1858-11-17 00:00)
+	JD_UNIX_EPOCH = 2440587.5 // Julian Date of 1970-01-01 00:00
+	DAYS_CENTURY  = 36525.0   // days in a Julian century
+)
+
+const (
+	CALENDAR_GREGORIAN_SWITCH = iota //Julian calendar until 1582 October 4, Gregorian from 1582 October 15
+	CALENDAR_GREGORIAN               //proleptic Gregorian calendar (as package time)
+	CALENDAR_JULIAN                  //proleptic Julian calendar
+)
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Julian Date of a calendar date and time, Meeus 7.1 as in NREL's spa.c
+// Note: year is numbered astronomically (year 0 is 1 BC), tz is the time zone of the date and
+// time [hours, negative west of Greenwich]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Julian_day(year, month, day, hour, minute int, second, tz float64, calendar int) float64 {
+	var day_decimal, julian_day, a float64
+
+	day_decimal = float64(day) + (float64(hour)-tz+(float64(minute)+second/60.0)/60.0)/24.0
+
+	if month < 3 {
+		month += 12
+		year--
+	}
+
+	julian_day = float64(int(365.25*(float64(year)+4716.0))) + float64(int((30.6001 * (float64(month) + 1)))) + day_decimal - 1524.5
+
+	if calendar == CALENDAR_GREGORIAN || (calendar == CALENDAR_GREGORIAN_SWITCH && julian_day > 2299160.0) {
+		// floored, so that proleptic Gregorian years before 1 AD also hold
+		a = math.Floor(float64(year) / 100.0)
+		julian_day += (2 - a + math.Floor(a/4))
+	}
+
+	return julian_day
+}
+
+// Julian Date of a clock reading
+func Jd(t time.Time) float64 {
+	u := t.UTC()
+
+	return Julian_day(u.Year(), int(u.Month()), u.Day(), u.Hour(), u.Minute(),
+		float64(u.Second())+float64(u.Nanosecond())/1e9, 0, CALENDAR_GREGORIAN)
+}
+
+// clock reading (in UTC) of a Julian Date (a float64 Julian Date resolves about 40 microseconds)
+func Jd_time(jd float64) time.Time {
+	days := math.Floor(jd - JD_UNIX_EPOCH)
+	ns := math.Round((jd - JD_UNIX_EPOCH - days) * 86400e9)
+
+	return time.Unix(int64(days)*86400, int64(ns)).UTC()
+}
+
+// Modified Julian Date of a clock reading
+func Mjd(t time.Time) float64 {
+	return Jd(t) - MJD_ZERO
+}
+
+// clock reading (in UTC) of a Modified Julian Date
+func Mjd_time(mjd float64) time.Time {
+	return Jd_time(mjd + MJD_ZERO)
+}
+
+// Modified Julian Date of a Julian Date
+func Jd_to_mjd(jd float64) float64 {
+	return jd - MJD_ZERO
+}
+
+// Julian Date of a Modified Julian Date
+func Mjd_to_jd(mjd float64) float64 {
+	return mjd + MJD_ZERO
+}
+
+// Julian centuries from J2000.0 of a Julian Date
+func J2000_centuries(jd float64) float64 {
+	return (jd - J2000) / DAYS_CENTURY
+}
+
+// Julian Date of a number of Julian centuries from J2000.0
+func Centuries_jd(centuries float64) float64 {
+	return J2000 + centuries*DAYS_CENTURY
+}
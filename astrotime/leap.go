@@ -0,0 +1,55 @@
+package astrotime
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoLeapSeconds is returned by Builtin_leap_seconds for instants before 1972, when UTC did
+// not yet step by whole seconds.
+var ErrNoLeapSeconds = errors.New("astrotime: no TAI-UTC before 1972")
+
+// Builtin_leap_seconds holds every leap second of UTC from 1972 to the one at the end of 2016.
+// It gives TAI-UTC = 37 seconds for every later instant, so set Time_scales.Leap to a current
+// table (e.g. from iers.Parse_leap_seconds) once a new leap second is announced.
+var Builtin_leap_seconds Leap_seconds = builtin_leap_table{}
+
+// first day of each TAI-UTC value (from 10 seconds on 1972-01-01, one more each step)
+var builtin_leap_dates = [...]struct {
+	year  int
+	month time.Month
+}{
+	{1972, 1}, {1972, 7}, {1973, 1}, {1974, 1}, {1975, 1}, {1976, 1}, {1977, 1}, {1978, 1},
+	{1979, 1}, {1980, 1}, {1981, 7}, {1982, 7}, {1983, 7}, {1985, 7}, {1988, 1}, {1990, 1},
+	{1991, 1}, {1992, 7}, {1993, 7}, {1994, 7}, {1996, 1}, {1997, 7}, {1999, 1}, {2006, 1},
+	{2009, 1}, {2012, 7}, {2015, 7}, {2017, 1},
+}
+
+// Leap_step is a step of TAI-UTC.
+type Leap_step struct {
+	Utc     time.Time // first instant (0h UTC of the first day) of the value
+	Tai_utc float64   // TAI-UTC [seconds]
+}
+
+// Builtin_leap_steps returns the steps of Builtin_leap_seconds, the first on 1972-01-01.
+func Builtin_leap_steps() []Leap_step {
+	steps := make([]Leap_step, len(builtin_leap_dates))
+	for i, d := range builtin_leap_dates {
+		steps[i] = Leap_step{Utc: time.Date(d.year, d.month, 1, 0, 0, 0, 0, time.UTC), Tai_utc: float64(10 + i)}
+	}
+
+	return steps
+}
+
+type builtin_leap_table struct{}
+
+func (builtin_leap_table) TAI_UTC(utc time.Time) (float64, error) {
+	for i := len(builtin_leap_dates) - 1; i >= 0; i-- {
+		d := builtin_leap_dates[i]
+		if !utc.Before(time.Date(d.year, d.month, 1, 0, 0, 0, 0, time.UTC)) {
+			return float64(10 + i), nil
+		}
+	}
+
+	return 0, ErrNoLeapSeconds
+}
@@ -0,0 +1,145 @@
+package astrotime
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	TT_TAI  = 32.184 // TT - TAI [seconds]
+	TAI_GPS = 19.0   // TAI - GPS time [seconds]
+
+	SCALE_ITERATIONS = 2 // fixed point iterations to find the UTC instant of a clock reading
+)
+
+// Scale is a time scale.
+type Scale int
+
+const (
+	UTC Scale = iota //Coordinated Universal Time
+	UT1              //Universal Time (earth rotation angle)
+	TAI              //International Atomic Time
+	TT               //Terrestrial Time
+	GPS              //GPS time (TAI - 19 seconds)
+)
+
+func (s Scale) String() string {
+	switch s {
+	case UTC:
+		return "UTC"
+	case UT1:
+		return "UT1"
+	case TAI:
+		return "TAI"
+	case TT:
+		return "TT"
+	case GPS:
+		return "GPS"
+	}
+
+	return fmt.Sprintf("Scale(%d)", int(s))
+}
+
+// Leap_seconds supplies TAI-UTC for a UTC instant, e.g. *iers.Leap_table or *iers.Data.
+type Leap_seconds interface {
+	TAI_UTC(utc time.Time) (float64, error) // TAI-UTC [seconds]
+}
+
+// Ut1_utc supplies UT1-UTC for a UTC instant, e.g. *iers.Eop_table or *iers.Data.
+type Ut1_utc interface {
+	DUT1(utc time.Time) (float64, error) // UT1-UTC [seconds]
+}
+
+// Time_scales converts clock readings between time scales. The zero value uses
+// Builtin_leap_seconds and takes UT1 as UTC.
+type Time_scales struct {
+	Leap Leap_seconds // TAI-UTC table; nil uses Builtin_leap_seconds
+	Dut1 Ut1_utc      // UT1-UTC values; nil takes UT1 as UTC (they differ by less than 0.9 seconds)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Clock reading of a scale minus the UTC reading [seconds] at the UTC instant utc
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (s *Time_scales) Offset(scale Scale, utc time.Time) (float64, error) {
+	switch scale {
+	case UTC:
+		return 0, nil
+	case UT1:
+		if s.Dut1 == nil {
+			return 0, nil
+		}
+		return s.Dut1.DUT1(utc)
+	}
+
+	leap := s.Leap
+	if leap == nil {
+		leap = Builtin_leap_seconds
+	}
+	tai_utc, err := leap.TAI_UTC(utc)
+	if err != nil {
+		return 0, err
+	}
+
+	switch scale {
+	case TAI:
+		return tai_utc, nil
+	case TT:
+		return tai_utc + TT_TAI, nil
+	case GPS:
+		return tai_utc - TAI_GPS, nil
+	}
+
+	return 0, fmt.Errorf("astrotime: unknown time scale %v", scale)
+}
+
+func add_seconds(t time.Time, seconds float64) time.Time {
+	return t.Add(time.Duration(math.Round(seconds * 1e9)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// UTC instant of the clock reading t of a scale
+// Note: The UTC reading of an instant inside an inserted leap second is the second after it
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (s *Time_scales) Utc(t time.Time, scale Scale) (time.Time, error) {
+	utc := t
+
+	for i := 0; i < SCALE_ITERATIONS; i++ {
+		offset, err := s.Offset(scale, utc)
+		if err != nil {
+			return time.Time{}, err
+		}
+		utc = add_seconds(t, -offset)
+	}
+
+	return utc.UTC(), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Convert the clock reading t of the scale from to the reading of the scale to
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (s *Time_scales) Convert(t time.Time, from, to Scale) (time.Time, error) {
+	utc, err := s.Utc(t, from)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	offset, err := s.Offset(to, utc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return add_seconds(utc, offset), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Julian Date in a scale of the UTC instant utc (e.g. TT for the Julian ephemeris day)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (s *Time_scales) Jd(utc time.Time, scale Scale) (float64, error) {
+	offset, err := s.Offset(scale, utc)
+	if err != nil {
+		return 0, err
+	}
+
+	return Jd(utc) + offset/86400.0, nil
+}
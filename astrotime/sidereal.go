@@ -0,0 +1,40 @@
+package astrotime
+
+import (
+	"math"
+
+	"github.com/Spectrafy/gosolar/internal/angle"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Greenwich mean sidereal time [degrees] at the Julian Date jd_ut1 (UT1), Meeus 12.4
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Gmst(jd_ut1 float64) float64 {
+	t := J2000_centuries(jd_ut1)
+
+	return angle.Limit_degrees(280.46061837 + 360.98564736629*(jd_ut1-J2000) + t*t*(0.000387933-t/38710000.0))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Equation of the equinoxes, apparent minus mean sidereal time [degrees]
+// Note: del_psi is the nutation in longitude and epsilon the true obliquity [degrees], e.g.
+// from gosolar.Spa_nutation
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Equation_of_equinoxes(del_psi, epsilon float64) float64 {
+	return del_psi * math.Cos(angle.Deg2rad(epsilon))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Greenwich apparent sidereal time [degrees] at the Julian Date jd_ut1 (UT1)
+// Note: del_psi and epsilon are the nutation of the same instant (see Equation_of_equinoxes)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Gast(jd_ut1, del_psi, epsilon float64) float64 {
+	return angle.Limit_degrees(Gmst(jd_ut1) + Equation_of_equinoxes(del_psi, epsilon))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Local apparent sidereal time [degrees] at a longitude (negative west of Greenwich) [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Lst(jd_ut1, del_psi, epsilon, longitude float64) float64 {
+	return angle.Limit_degrees(Gast(jd_ut1, del_psi, epsilon) + longitude)
+}
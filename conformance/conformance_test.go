@@ -0,0 +1,178 @@
+package conformance
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/Spectrafy/gosolar"
+)
+
+// NREL technical report example: 17 October 2003, 12:30:30 local time, Golden, Colorado
+func report_example() gosolar.Spa_data {
+	return gosolar.Spa_data{
+		Year:          2003,
+		Month:         10,
+		Day:           17,
+		Hour:          12,
+		Minute:        30,
+		Second:        30,
+		Timezone:      -7.0,
+		Delta_ut1:     0,
+		Delta_t:       67,
+		Longitude:     -105.1786,
+		Latitude:      39.742476,
+		Elevation:     1830.14,
+		Pressure:      820,
+		Temperature:   11,
+		Slope:         30,
+		Azm_rotation:  -10,
+		Atmos_refract: 0.5667,
+		Refraction:    gosolar.Spa_refraction_nrel{},
+		Function:      gosolar.SPA_ALL,
+	}
+}
+
+// a reference output with the tolerance it is checked to
+type field struct {
+	name      string
+	value     func(r *gosolar.Spa_result) float64
+	tolerance float64
+}
+
+var fields = []field{
+	{"Jd", func(r *gosolar.Spa_result) float64 { return r.Jd }, 1e-6},
+	{"L", func(r *gosolar.Spa_result) float64 { return r.L }, 1e-8},
+	{"B", func(r *gosolar.Spa_result) float64 { return r.B }, 1e-9},
+	{"R", func(r *gosolar.Spa_result) float64 { return r.R }, 1e-9},
+	{"Del_psi", func(r *gosolar.Spa_result) float64 { return r.Del_psi }, 1e-9},
+	{"Epsilon", func(r *gosolar.Spa_result) float64 { return r.Epsilon }, 1e-9},
+	{"Zenith", func(r *gosolar.Spa_result) float64 { return r.Zenith }, 1e-7},
+	{"Azimuth", func(r *gosolar.Spa_result) float64 { return r.Azimuth }, 1e-7},
+	{"Incidence", func(r *gosolar.Spa_result) float64 { return r.Incidence }, 1e-7},
+	{"Sunrise", func(r *gosolar.Spa_result) float64 { return r.Sunrise }, 1e-7},
+	{"Sunset", func(r *gosolar.Spa_result) float64 { return r.Sunset }, 1e-7},
+	{"Eot", func(r *gosolar.Spa_result) float64 { return r.Eot }, 1e-7},
+}
+
+func TestReport_example(t *testing.T) {
+	// published values, to the digits given in the report (in the order of fields)
+	want := []struct {
+		name      string
+		value     float64
+		tolerance float64
+	}{
+		{"Jd", 2452930.312847, 5e-7},
+		{"L", 24.0182616917, 5e-11},
+		{"B", -0.0001011219, 5e-11},
+		{"R", 0.9965422974, 5e-11},
+		{"Del_psi", -0.00399840, 5e-9},
+		{"Epsilon", 23.440465, 5e-7},
+		{"Zenith", 50.11162, 5e-6},
+		{"Azimuth", 194.34024, 5e-6},
+		{"Incidence", 25.18700, 5e-6},
+		{"Sunrise", 6 + 12.0/60 + 43.0/3600, 0.5 / 3600}, // 06:12:43 local time
+		{"Sunset", 17 + 20.0/60 + 19.0/3600, 0.5 / 3600}, // 17:20:19 local time
+	}
+
+	r, err := gosolar.Spa_compute(report_example())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, w := range want {
+		if got := fields[i].value(&r); math.Abs(got-w.value) > w.tolerance {
+			t.Errorf("%s = %.10f, report gives %.10f", w.name, got, w.value)
+		}
+	}
+}
+
+func TestReport_julian_day(t *testing.T) {
+	// Julian days of the report's table of dates (Table A4.1) for the years -2000 to 6000
+	for _, tt := range []struct {
+		year, month, day, hour, minute int
+		jd                             float64
+	}{
+		{2000, 1, 1, 12, 0, 2451545.0},
+		{1999, 1, 1, 0, 0, 2451179.5},
+		{1987, 1, 27, 0, 0, 2446822.5},
+		{1987, 6, 19, 12, 0, 2446966.0},
+		{1988, 1, 27, 0, 0, 2447187.5},
+		{1988, 6, 19, 12, 0, 2447332.0},
+		{1900, 1, 1, 0, 0, 2415020.5},
+		{1600, 1, 1, 0, 0, 2305447.5},
+		{1600, 12, 31, 0, 0, 2305812.5},
+		{837, 4, 10, 7, 12, 2026871.8},
+		{-123, 12, 31, 0, 0, 1676496.5},
+		{-122, 1, 1, 0, 0, 1676497.5},
+		{-1000, 7, 12, 12, 0, 1356001.0},
+		{-1000, 2, 29, 0, 0, 1355866.5},
+		{-1001, 8, 17, 21, 36, 1355671.4},
+	} {
+		spa := report_example()
+		spa.Year, spa.Month, spa.Day = tt.year, tt.month, tt.day
+		spa.Hour, spa.Minute, spa.Second, spa.Timezone = tt.hour, tt.minute, 0, 0
+		spa.Function = gosolar.SPA_ZA
+
+		r, err := gosolar.Spa_compute(spa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(r.Jd-tt.jd) > 1e-6 {
+			t.Errorf("%d-%02d-%02d %02d:%02d: Jd = %.6f, report gives %.6f",
+				tt.year, tt.month, tt.day, tt.hour, tt.minute, r.Jd, tt.jd)
+		}
+	}
+}
+
+func TestReference_table(t *testing.T) {
+	f, err := os.Open("testdata/spa_reference.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const inputs = 17
+	for line, rec := range records {
+		if len(rec) != inputs+len(fields) {
+			t.Fatalf("record %d has %d values, want %d", line+1, len(rec), inputs+len(fields))
+		}
+
+		v := make([]float64, len(rec))
+		for i, s := range rec {
+			if v[i], err = strconv.ParseFloat(s, 64); err != nil {
+				t.Fatalf("record %d: %v", line+1, err)
+			}
+		}
+
+		spa := report_example()
+		spa.Year, spa.Month, spa.Day = int(v[0]), int(v[1]), int(v[2])
+		spa.Hour, spa.Minute, spa.Second = int(v[3]), int(v[4]), v[5]
+		spa.Timezone, spa.Delta_ut1, spa.Delta_t = v[6], v[7], v[8]
+		spa.Longitude, spa.Latitude, spa.Elevation = v[9], v[10], v[11]
+		spa.Pressure, spa.Temperature = v[12], v[13]
+		spa.Slope, spa.Azm_rotation, spa.Atmos_refract = v[14], v[15], v[16]
+
+		r, err := gosolar.Spa_compute(spa)
+		if err != nil {
+			t.Errorf("%s-%s-%s: %v", rec[0], rec[1], rec[2], err)
+			continue
+		}
+
+		for i, fd := range fields {
+			if got, want := fd.value(&r), v[inputs+i]; math.Abs(got-want) > fd.tolerance {
+				t.Errorf("%s-%s-%s %s:%s:%s: %s = %.10f, reference %.10f (tolerance %g)",
+					rec[0], rec[1], rec[2], rec[3], rec[4], rec[5], fd.name, got, want, fd.tolerance)
+			}
+		}
+	}
+}
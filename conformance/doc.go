@@ -0,0 +1,16 @@
+// Package conformance checks gosolar against reference outputs of the NREL Solar Position
+// Algorithm. It holds tests only:
+//
+//	go test ./conformance
+//
+// The technical report example (17 October 2003, Golden, Colorado) is checked against the
+// values published in the report, the Julian day against the report's table of dates, and
+// testdata/spa_reference.csv holds a table of NREL SPA outputs over the years -2000 to 6000
+// (Julian day, earth heliocentric longitude, latitude and radius vector, nutation longitude,
+// obliquity, zenith, azimuth, incidence, sunrise, sunset and equation of time). The table is
+// the output of testdata/spa_reference.c, a C transcription of spa.c; its header records the
+// spa.c revision and the compiler it was generated with.
+//
+// The reference is the algorithm of NREL's spa.c, so the calculations use the refraction of
+// the report (Spa_refraction_nrel), not the piecewise default of Spa_data.Refraction.
+package conformance
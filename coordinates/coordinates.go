@@ -0,0 +1,138 @@
+// Package coordinates converts positions of arbitrary targets between the ecliptic,
+// equatorial, hour-angle and horizontal frames, applies the diurnal parallax and atmospheric
+// refraction of an observer, and converts each frame to and from unit vectors.
+//
+// The chain used by the SPA for the sun is
+//
+//	ecliptic --(obliquity)--> equatorial --(local sidereal time)--> hour angle
+//	  --(parallax)--> topocentric hour angle --(latitude)--> horizontal --(refraction)--> apparent
+//
+// All angles are in degrees. Longitudes are positive east of Greenwich, hour angles are
+// measured westward from the meridian and azimuths eastward from north (as Spa_data.Azimuth).
+// The true obliquity and apparent sidereal time come from gosolar.Spa_nutation and
+// astrotime.Gast or astrotime.Lst. The SPA computes its own sun chain with these functions.
+package coordinates
+
+import (
+	"math"
+
+	"github.com/Spectrafy/gosolar/internal/angle"
+)
+
+// Ecliptic coordinates referred to the ecliptic and equinox of date
+type Ecliptic struct {
+	Longitude float64 // ecliptic longitude [degrees]
+	Latitude  float64 // ecliptic latitude [degrees]
+}
+
+// Equatorial coordinates, geocentric or topocentric
+type Equatorial struct {
+	Right_ascension float64 // right ascension [degrees]
+	Declination     float64 // declination [degrees]
+}
+
+// Hour_angle coordinates of the local meridian, geocentric or topocentric
+type Hour_angle struct {
+	Hour_angle  float64 // hour angle (westward from the meridian) [degrees]
+	Declination float64 // declination [degrees]
+}
+
+// Horizontal coordinates of the observer
+type Horizontal struct {
+	Azimuth   float64 // azimuth (eastward from north) [degrees]
+	Elevation float64 // elevation above the horizontal [degrees]
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Equatorial coordinates of ecliptic coordinates with the obliquity epsilon [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Ecliptic_to_equatorial(ecl Ecliptic, epsilon float64) Equatorial {
+	lamda_rad := angle.Deg2rad(ecl.Longitude)
+	beta_rad := angle.Deg2rad(ecl.Latitude)
+	epsilon_rad := angle.Deg2rad(epsilon)
+
+	return Equatorial{
+		Right_ascension: angle.Limit_degrees(angle.Rad2deg(math.Atan2(math.Sin(lamda_rad)*math.Cos(epsilon_rad)-
+			math.Tan(beta_rad)*math.Sin(epsilon_rad), math.Cos(lamda_rad)))),
+		Declination: angle.Rad2deg(math.Asin(math.Sin(beta_rad)*math.Cos(epsilon_rad) +
+			math.Cos(beta_rad)*math.Sin(epsilon_rad)*math.Sin(lamda_rad))),
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Ecliptic coordinates of equatorial coordinates with the obliquity epsilon [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Equatorial_to_ecliptic(eq Equatorial, epsilon float64) Ecliptic {
+	alpha_rad := angle.Deg2rad(eq.Right_ascension)
+	delta_rad := angle.Deg2rad(eq.Declination)
+	epsilon_rad := angle.Deg2rad(epsilon)
+
+	return Ecliptic{
+		Longitude: angle.Limit_degrees(angle.Rad2deg(math.Atan2(math.Sin(alpha_rad)*math.Cos(epsilon_rad)+
+			math.Tan(delta_rad)*math.Sin(epsilon_rad), math.Cos(alpha_rad)))),
+		Latitude: angle.Rad2deg(math.Asin(math.Sin(delta_rad)*math.Cos(epsilon_rad) -
+			math.Cos(delta_rad)*math.Sin(epsilon_rad)*math.Sin(alpha_rad))),
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Hour angle coordinates of equatorial coordinates at the local sidereal time lst [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Equatorial_to_hour_angle(eq Equatorial, lst float64) Hour_angle {
+	return Hour_angle{Hour_angle: angle.Limit_degrees(lst - eq.Right_ascension), Declination: eq.Declination}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Equatorial coordinates of hour angle coordinates at the local sidereal time lst [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Hour_angle_to_equatorial(ha Hour_angle, lst float64) Equatorial {
+	return Equatorial{Right_ascension: angle.Limit_degrees(lst - ha.Hour_angle), Declination: ha.Declination}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Horizontal coordinates of hour angle coordinates at the observer latitude [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Hour_angle_to_horizontal(ha Hour_angle, latitude float64) Horizontal {
+	h_rad := angle.Deg2rad(ha.Hour_angle)
+	delta_rad := angle.Deg2rad(ha.Declination)
+	lat_rad := angle.Deg2rad(latitude)
+
+	return Horizontal{
+		Azimuth: angle.Limit_degrees(angle.Rad2deg(math.Atan2(-math.Cos(delta_rad)*math.Sin(h_rad),
+			math.Sin(delta_rad)*math.Cos(lat_rad)-math.Cos(delta_rad)*math.Cos(h_rad)*math.Sin(lat_rad)))),
+		Elevation: angle.Rad2deg(math.Asin(math.Sin(lat_rad)*math.Sin(delta_rad) +
+			math.Cos(lat_rad)*math.Cos(delta_rad)*math.Cos(h_rad))),
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Hour angle coordinates of horizontal coordinates at the observer latitude [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Horizontal_to_hour_angle(hz Horizontal, latitude float64) Hour_angle {
+	a_rad := angle.Deg2rad(hz.Azimuth)
+	e_rad := angle.Deg2rad(hz.Elevation)
+	lat_rad := angle.Deg2rad(latitude)
+
+	return Hour_angle{
+		Hour_angle: angle.Limit_degrees(angle.Rad2deg(math.Atan2(-math.Cos(e_rad)*math.Sin(a_rad),
+			math.Cos(lat_rad)*math.Sin(e_rad)-math.Sin(lat_rad)*math.Cos(e_rad)*math.Cos(a_rad)))),
+		Declination: angle.Rad2deg(math.Asin(math.Sin(lat_rad)*math.Sin(e_rad) +
+			math.Cos(lat_rad)*math.Cos(e_rad)*math.Cos(a_rad))),
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Horizontal coordinates of equatorial coordinates at the local sidereal time lst and the
+// observer latitude [degrees] (no parallax or refraction)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Equatorial_to_horizontal(eq Equatorial, lst, latitude float64) Horizontal {
+	return Hour_angle_to_horizontal(Equatorial_to_hour_angle(eq, lst), latitude)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Equatorial coordinates of horizontal coordinates at the local sidereal time lst and the
+// observer latitude [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Horizontal_to_equatorial(hz Horizontal, lst, latitude float64) Equatorial {
+	return Hour_angle_to_equatorial(Horizontal_to_hour_angle(hz, latitude), lst)
+}
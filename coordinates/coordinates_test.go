@@ -0,0 +1,81 @@
+package coordinates
+
+import (
+	"math"
+	"testing"
+)
+
+func angle_diff(a, b float64) float64 {
+	return math.Abs(math.Remainder(a-b, 360.0))
+}
+
+func TestEcliptic_equatorial(t *testing.T) {
+	// Meeus example 13.a (Pollux)
+	eq := Equatorial{Right_ascension: 116.328942, Declination: 28.026183}
+	ecl := Equatorial_to_ecliptic(eq, 23.4392911)
+	if angle_diff(ecl.Longitude, 113.215630) > 1e-6 || math.Abs(ecl.Latitude-6.684170) > 1e-6 {
+		t.Errorf("Equatorial_to_ecliptic = %+v, want 113.215630, 6.684170", ecl)
+	}
+
+	back := Ecliptic_to_equatorial(ecl, 23.4392911)
+	if angle_diff(back.Right_ascension, eq.Right_ascension) > 1e-9 || math.Abs(back.Declination-eq.Declination) > 1e-9 {
+		t.Errorf("Ecliptic_to_equatorial = %+v, want %+v", back, eq)
+	}
+}
+
+func TestHour_angle_horizontal(t *testing.T) {
+	// Meeus example 13.b (Venus from Washington), azimuth there is westward from south
+	ha := Hour_angle{Hour_angle: 64.352133, Declination: -6.719892}
+	hz := Hour_angle_to_horizontal(ha, 38.921389)
+	if angle_diff(hz.Azimuth, 68.0337+180.0) > 1e-4 || math.Abs(hz.Elevation-15.1249) > 1e-4 {
+		t.Errorf("Hour_angle_to_horizontal = %+v, want 248.0337, 15.1249", hz)
+	}
+
+	for _, latitude := range []float64{-89, -45, 0, 38.921389, 70} {
+		for h := 5.0; h < 360; h += 35 {
+			ha := Hour_angle{Hour_angle: h, Declination: 23.4 - h/10}
+			back := Horizontal_to_hour_angle(Hour_angle_to_horizontal(ha, latitude), latitude)
+			if angle_diff(back.Hour_angle, ha.Hour_angle) > 1e-9 || math.Abs(back.Declination-ha.Declination) > 1e-9 {
+				t.Errorf("latitude %g: round trip of %+v = %+v", latitude, ha, back)
+			}
+		}
+	}
+}
+
+func TestVector(t *testing.T) {
+	const epsilon, lst, latitude = 23.44, 123.4, 52.5
+
+	for lon := 0.0; lon < 360; lon += 30 {
+		ecl := Ecliptic{Longitude: lon, Latitude: 5 - lon/60}
+		v := ecl.Vector()
+		if n := v.Norm(); math.Abs(n-1) > 1e-15 {
+			t.Errorf("%+v: norm %g", ecl, n)
+		}
+
+		eq := Ecliptic_to_equatorial(ecl, epsilon)
+		if s := Separation(v.Rotate_x(epsilon), eq.Vector()); s > 1e-9 {
+			t.Errorf("%+v: rotated ecliptic vector is %g degrees from the equatorial one", ecl, s)
+		}
+
+		ha := Equatorial_to_hour_angle(eq, lst)
+		if s := Separation(eq.Vector().Rotate_z(-lst), ha.Vector()); s > 1e-9 {
+			t.Errorf("%+v: rotated equatorial vector is %g degrees from the hour angle one", eq, s)
+		}
+
+		hz := Hour_angle_to_horizontal(ha, latitude)
+		back := hz.Vector().Horizontal()
+		if angle_diff(back.Azimuth, hz.Azimuth) > 1e-9 || math.Abs(back.Elevation-hz.Elevation) > 1e-9 {
+			t.Errorf("%+v: vector round trip %+v", hz, back)
+		}
+		if back := ha.Vector().Hour_angle(); angle_diff(back.Hour_angle, ha.Hour_angle) > 1e-9 {
+			t.Errorf("%+v: vector round trip %+v", ha, back)
+		}
+		if back := v.Ecliptic(); angle_diff(back.Longitude, ecl.Longitude) > 1e-9 {
+			t.Errorf("%+v: vector round trip %+v", ecl, back)
+		}
+	}
+
+	if s := Separation(Vector{X: 1}, Vector{X: -1, Y: 1e-12}); math.Abs(s-180) > 1e-9 {
+		t.Errorf("Separation of opposite vectors = %g", s)
+	}
+}
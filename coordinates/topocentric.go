@@ -0,0 +1,92 @@
+package coordinates
+
+import (
+	"math"
+
+	"github.com/Spectrafy/gosolar/internal/angle"
+)
+
+const (
+	PARALLAX_AU          = 8.794 / 3600.0 // equatorial horizontal parallax at 1 AU [degrees]
+	EARTH_EQUATOR_RADIUS = 6378140.0      // earth equatorial radius [meters]
+	EARTH_AXIS_RATIO     = 0.99664719     // earth polar over equatorial radius
+)
+
+// Refraction is an atmospheric refraction model applied to the atmosphere of one observer,
+// e.g. gosolar.Spa_atmosphere_refraction.
+type Refraction interface {
+	// refraction [degrees] to add to the true (airless) elevation angle e0 [degrees]
+	Correction(e0 float64) float64
+	// refraction [degrees] of a body seen at the apparent elevation angle h [degrees]
+	Apparent_correction(h float64) float64
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Equatorial horizontal parallax [degrees] of a target at the geocentric distance r [AU]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parallax(r float64) float64 {
+	return angle.Rad2deg(math.Asin(math.Sin(angle.Deg2rad(PARALLAX_AU)) / r))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parallax in right ascension del_alpha and topocentric declination delta_prime [degrees] of
+// geocentric hour angle coordinates for a target with the equatorial horizontal parallax xi
+// [degrees], seen from the observer latitude [degrees] and elevation [meters] (Meeus 40)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parallax_in_right_ascension_and_declination(ha Hour_angle, xi, latitude, elevation float64) (del_alpha, delta_prime float64) {
+	lat_rad := angle.Deg2rad(latitude)
+	xi_rad := angle.Deg2rad(xi)
+	h_rad := angle.Deg2rad(ha.Hour_angle)
+	delta_rad := angle.Deg2rad(ha.Declination)
+	u := math.Atan(EARTH_AXIS_RATIO * math.Tan(lat_rad))
+	y := EARTH_AXIS_RATIO*math.Sin(u) + elevation*math.Sin(lat_rad)/EARTH_EQUATOR_RADIUS
+	x := math.Cos(u) + elevation*math.Cos(lat_rad)/EARTH_EQUATOR_RADIUS
+
+	del_alpha_rad := math.Atan2(-x*math.Sin(xi_rad)*math.Sin(h_rad),
+		math.Cos(delta_rad)-x*math.Sin(xi_rad)*math.Cos(h_rad))
+
+	delta_prime = angle.Rad2deg(math.Atan2((math.Sin(delta_rad)-y*math.Sin(xi_rad))*math.Cos(del_alpha_rad),
+		math.Cos(delta_rad)-x*math.Sin(xi_rad)*math.Cos(h_rad)))
+
+	return angle.Rad2deg(del_alpha_rad), delta_prime
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Topocentric hour angle coordinates of geocentric ones for a target with the equatorial
+// horizontal parallax xi [degrees], seen from the observer latitude and elevation
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Topocentric_hour_angle(ha Hour_angle, xi, latitude, elevation float64) Hour_angle {
+	del_alpha, delta_prime := Parallax_in_right_ascension_and_declination(ha, xi, latitude, elevation)
+
+	return Hour_angle{Hour_angle: angle.Limit_degrees(ha.Hour_angle - del_alpha), Declination: delta_prime}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Topocentric equatorial coordinates of geocentric ones at the local sidereal time lst for a
+// target with the equatorial horizontal parallax xi [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Topocentric_equatorial(eq Equatorial, lst, xi, latitude, elevation float64) Equatorial {
+	del_alpha, delta_prime := Parallax_in_right_ascension_and_declination(Equatorial_to_hour_angle(eq, lst), xi,
+		latitude, elevation)
+
+	return Equatorial{Right_ascension: angle.Limit_degrees(eq.Right_ascension + del_alpha),
+		Declination: delta_prime}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Apparent horizontal coordinates of true (airless) ones, refracted with the model
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Refract(hz Horizontal, model Refraction) Horizontal {
+	hz.Elevation += model.Correction(hz.Elevation)
+
+	return hz
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// True (airless) horizontal coordinates of apparent ones, the inverse of Refract
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Unrefract(hz Horizontal, model Refraction) Horizontal {
+	hz.Elevation -= model.Apparent_correction(hz.Elevation)
+
+	return hz
+}
@@ -0,0 +1,111 @@
+package coordinates
+
+import (
+	"math"
+
+	"github.com/Spectrafy/gosolar/internal/angle"
+)
+
+// Vector is a unit vector (or any cartesian vector) in the frame of a coordinate type:
+//
+//	Ecliptic    X to the equinox, Z to the north ecliptic pole
+//	Equatorial  X to the equinox, Z to the north celestial pole
+//	Hour_angle  X to the meridian on the equator, Y to the east point, Z to the north pole
+//	Horizontal  X to the north point, Y to the east point, Z to the zenith
+//
+// Frames are right-handed, so the equatorial vector of an ecliptic vector is
+// v.Rotate_x(epsilon) and its hour angle vector v.Rotate_z(-lst).
+type Vector struct {
+	X, Y, Z float64
+}
+
+func spherical_to_vector(lon, lat float64) Vector {
+	lon_rad := angle.Deg2rad(lon)
+	lat_rad := angle.Deg2rad(lat)
+
+	return Vector{X: math.Cos(lat_rad) * math.Cos(lon_rad), Y: math.Cos(lat_rad) * math.Sin(lon_rad), Z: math.Sin(lat_rad)}
+}
+
+func vector_to_spherical(v Vector) (lon, lat float64) {
+	return angle.Limit_degrees(angle.Rad2deg(math.Atan2(v.Y, v.X))), angle.Rad2deg(math.Atan2(v.Z, math.Hypot(v.X, v.Y)))
+}
+
+func (ecl Ecliptic) Vector() Vector {
+	return spherical_to_vector(ecl.Longitude, ecl.Latitude)
+}
+
+func (eq Equatorial) Vector() Vector {
+	return spherical_to_vector(eq.Right_ascension, eq.Declination)
+}
+
+func (ha Hour_angle) Vector() Vector {
+	return spherical_to_vector(-ha.Hour_angle, ha.Declination)
+}
+
+func (hz Horizontal) Vector() Vector {
+	return spherical_to_vector(hz.Azimuth, hz.Elevation)
+}
+
+func (v Vector) Ecliptic() Ecliptic {
+	lon, lat := vector_to_spherical(v)
+
+	return Ecliptic{Longitude: lon, Latitude: lat}
+}
+
+func (v Vector) Equatorial() Equatorial {
+	lon, lat := vector_to_spherical(v)
+
+	return Equatorial{Right_ascension: lon, Declination: lat}
+}
+
+func (v Vector) Hour_angle() Hour_angle {
+	lon, lat := vector_to_spherical(v)
+
+	return Hour_angle{Hour_angle: angle.Limit_degrees(-lon), Declination: lat}
+}
+
+func (v Vector) Horizontal() Horizontal {
+	lon, lat := vector_to_spherical(v)
+
+	return Horizontal{Azimuth: lon, Elevation: lat}
+}
+
+func (v Vector) Dot(w Vector) float64 {
+	return v.X*w.X + v.Y*w.Y + v.Z*w.Z
+}
+
+func (v Vector) Cross(w Vector) Vector {
+	return Vector{X: v.Y*w.Z - v.Z*w.Y, Y: v.Z*w.X - v.X*w.Z, Z: v.X*w.Y - v.Y*w.X}
+}
+
+func (v Vector) Norm() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Vector rotated counterclockwise by degrees about the X, Y or Z axis
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (v Vector) Rotate_x(degrees float64) Vector {
+	s, c := math.Sincos(angle.Deg2rad(degrees))
+
+	return Vector{X: v.X, Y: c*v.Y - s*v.Z, Z: s*v.Y + c*v.Z}
+}
+
+func (v Vector) Rotate_y(degrees float64) Vector {
+	s, c := math.Sincos(angle.Deg2rad(degrees))
+
+	return Vector{X: c*v.X + s*v.Z, Y: v.Y, Z: -s*v.X + c*v.Z}
+}
+
+func (v Vector) Rotate_z(degrees float64) Vector {
+	s, c := math.Sincos(angle.Deg2rad(degrees))
+
+	return Vector{X: c*v.X - s*v.Y, Y: s*v.X + c*v.Y, Z: v.Z}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Angle between two vectors [degrees], accurate for small and near 180 degree separations
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Separation(v, w Vector) float64 {
+	return angle.Rad2deg(math.Atan2(v.Cross(w).Norm(), v.Dot(w)))
+}
@@ -0,0 +1,109 @@
+package gosolar
+
+/////////////////////////////////////////////////////////////////////////
+//  Delta T (TT - UT) estimation
+//
+//  Observed values are taken from the USNO/IERS table of Delta T at the
+//  start of each year. Outside that table the polynomial expressions of
+//  Espenak & Meeus ("Five Millennium Canon of Solar Eclipses", NASA
+//  TP-2006-214141) are used, with their long-term parabola beyond the
+//  years -500 and 2150. The published fits do not meet exactly: the
+//  estimate jumps by up to 0.25 s at their boundaries (at 1600) and by
+//  0.16 s where the observed table starts.
+/////////////////////////////////////////////////////////////////////////
+
+const (
+	DELTA_T_TABLE_FIRST = 1973 // first year of DELTA_T_TABLE
+	DELTA_T_BLEND_END   = 2050 // year where the extrapolation joins the Espenak-Meeus fit
+)
+
+// observed Delta T [seconds] on January 1 of each year from DELTA_T_TABLE_FIRST
+var DELTA_T_TABLE = []float64{
+	43.4724, 44.4841, 45.4761, 46.4567, 47.5214, 48.5344, 49.5861, 50.5387, // 1973-1980
+	51.3808, 52.1668, 52.9565, 53.7882, 54.3427, 54.8712, 55.3222, 55.8197, // 1981-1988
+	56.3000, 56.8553, 57.5653, 58.3092, 59.1218, 59.9845, 60.7853, 61.6287, // 1989-1996
+	62.2950, 62.9659, 63.4673, 63.8285, 64.0908, 64.2998, 64.4734, 64.5736, // 1997-2004
+	64.6876, 64.8452, 65.1464, 65.4573, 65.7768, 66.0699, 66.3246, 66.6030, // 2005-2012
+	66.9069, 67.2810, 67.6439, 68.1024, 68.5927, 68.9676, 69.2201, 69.3612, // 2013-2020
+}
+
+func delta_t_polynomial(y float64) float64 {
+	var u, t float64
+
+	switch {
+	case y < -500:
+		u = (y - 1820) / 100
+		return -20 + 32*u*u
+	case y < 500:
+		u = y / 100
+		return 10583.6 + u*(-1014.41+u*(33.78311+u*(-5.952053+u*(-0.1798452+u*(0.022174192+u*0.0090316521)))))
+	case y < 1600:
+		u = (y - 1000) / 100
+		return 1574.2 + u*(-556.01+u*(71.23472+u*(0.319781+u*(-0.8503463+u*(-0.005050998+u*0.0083572073)))))
+	case y < 1700:
+		t = y - 1600
+		return 120 + t*(-0.9808+t*(-0.01532+t/7129))
+	case y < 1800:
+		t = y - 1700
+		return 8.83 + t*(0.1603+t*(-0.0059285+t*(0.00013336-t/1174000)))
+	case y < 1860:
+		t = y - 1800
+		return 13.72 + t*(-0.332447+t*(0.0068612+t*(0.0041116+t*(-0.00037436+
+			t*(0.0000121272+t*(-0.0000001699+t*0.000000000875))))))
+	case y < 1900:
+		t = y - 1860
+		return 7.62 + t*(0.5737+t*(-0.251754+t*(0.01680668+t*(-0.0004473624+t/233174))))
+	case y < 1920:
+		t = y - 1900
+		return -2.79 + t*(1.494119+t*(-0.0598939+t*(0.0061966-t*0.000197)))
+	case y < 1941:
+		t = y - 1920
+		return 21.20 + t*(0.84493+t*(-0.076100+t*0.0020936))
+	case y < 1961:
+		t = y - 1950
+		return 29.07 + t*(0.407+t*(-1/233.0+t/2547))
+	case y < 1986:
+		t = y - 1975
+		return 45.45 + t*(1.067+t*(-1/260.0-t/718))
+	case y < 2005:
+		t = y - 2000
+		return 63.86 + t*(0.3345+t*(-0.060374+t*(0.0017275+t*(0.000651814+t*0.00002373599))))
+	case y < 2050:
+		t = y - 2000
+		return 62.92 + t*(0.32217+t*0.005589)
+	case y < 2150:
+		u = (y - 1820) / 100
+		return -20 + 32*u*u - 0.5628*(2150-y)
+	}
+
+	u = (y - 1820) / 100
+	return -20 + 32*u*u
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Estimate Delta T [seconds] for a decimal year (e.g. 2003.79 for mid October 2003)
+//
+// Inside the observed table the yearly values are interpolated linearly. After the end of the
+// table, the difference between the last observed value and the polynomial fit is faded out
+// linearly until DELTA_T_BLEND_END, so the estimate has no jump where the table stops.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_delta_t(year float64) float64 {
+	last := float64(DELTA_T_TABLE_FIRST + len(DELTA_T_TABLE) - 1)
+
+	if year >= DELTA_T_TABLE_FIRST && year < last {
+		f := year - DELTA_T_TABLE_FIRST
+		i := integer(f)
+		return DELTA_T_TABLE[i] + (f-float64(i))*(DELTA_T_TABLE[i+1]-DELTA_T_TABLE[i])
+	}
+
+	if year >= last && year < DELTA_T_BLEND_END {
+		offset := DELTA_T_TABLE[len(DELTA_T_TABLE)-1] - delta_t_polynomial(last)
+		return delta_t_polynomial(year) + offset*(DELTA_T_BLEND_END-year)/(DELTA_T_BLEND_END-last)
+	}
+
+	return delta_t_polynomial(year)
+}
+
+func delta_t_decimal_year(year, month int) float64 {
+	return float64(year) + (float64(month)-0.5)/12.0
+}
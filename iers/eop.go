@@ -0,0 +1,203 @@
+package iers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Spectrafy/gosolar/astrotime"
+)
+
+const (
+	MAX_EOP_GAP = 10.0 // longest span between two UT1-UTC values that is interpolated [days]
+)
+
+// Eop_table holds daily UT1-UTC values, sorted by date.
+type Eop_table struct {
+	Mjd       []float64 // Modified Julian Date (0h UTC) of each value
+	Dut1      []float64 // UT1-UTC [seconds]
+	Predicted []bool    // value is a prediction rather than an observation
+}
+
+func (e *Eop_table) add(mjd, dut1 float64, predicted bool) {
+	i := sort.SearchFloat64s(e.Mjd, mjd)
+
+	if i < len(e.Mjd) && e.Mjd[i] == mjd {
+		// an observed value always wins over a prediction for the same day
+		if !predicted || e.Predicted[i] {
+			e.Dut1[i], e.Predicted[i] = dut1, predicted
+		}
+		return
+	}
+
+	e.Mjd = append(e.Mjd, 0)
+	e.Dut1 = append(e.Dut1, 0)
+	e.Predicted = append(e.Predicted, false)
+	copy(e.Mjd[i+1:], e.Mjd[i:])
+	copy(e.Dut1[i+1:], e.Dut1[i:])
+	copy(e.Predicted[i+1:], e.Predicted[i:])
+	e.Mjd[i], e.Dut1[i], e.Predicted[i] = mjd, dut1, predicted
+}
+
+// Merge adds the values of o to e. Observed values replace predictions for the same day.
+func (e *Eop_table) Merge(o *Eop_table) {
+	for i := range o.Mjd {
+		e.add(o.Mjd[i], o.Dut1[i], o.Predicted[i])
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// UT1-UTC [seconds] at t, linearly interpolated between the daily values
+//
+// When a leap second falls between two daily values, UT1-UTC jumps by one second at 0h UTC;
+// the interpolation removes that jump first, so the result is continuous in UT1 and keeps the
+// pre-leap value up to the end of the day that holds the leap second.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (e *Eop_table) DUT1(t time.Time) (float64, error) {
+	n := len(e.Mjd)
+	mjd := astrotime.Mjd(t)
+
+	if n == 0 || mjd < e.Mjd[0] || mjd > e.Mjd[n-1] {
+		err := &Range_error{Table: "UT1-UTC", Time: t}
+		if n > 0 {
+			err.First, err.Last = astrotime.Mjd_time(e.Mjd[0]), astrotime.Mjd_time(e.Mjd[n-1])
+		}
+		return 0, err
+	}
+
+	i := sort.SearchFloat64s(e.Mjd, mjd)
+	if e.Mjd[i] == mjd {
+		return e.Dut1[i], nil
+	}
+	if e.Mjd[i]-e.Mjd[i-1] > MAX_EOP_GAP {
+		return 0, &Range_error{Table: "UT1-UTC", Time: t, Gap: true,
+			First: astrotime.Mjd_time(e.Mjd[i-1]), Last: astrotime.Mjd_time(e.Mjd[i])}
+	}
+
+	d0, d1 := e.Dut1[i-1], e.Dut1[i]
+	if d1-d0 > 0.5 {
+		d1 -= 1.0
+	} else if d1-d0 < -0.5 {
+		d1 += 1.0
+	}
+
+	return d0 + (d1-d0)*(mjd-e.Mjd[i-1])/(e.Mjd[i]-e.Mjd[i-1]), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse an IERS finals2000A (or finals) file
+//
+// Fixed format columns used (1-based): 8-15 MJD, 58 UT1-UTC flag (I = IERS, P = prediction),
+// 59-68 UT1-UTC [seconds] (Bulletin A). Lines without a UT1-UTC value (at the end of the
+// prediction span) are skipped.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_finals(r io.Reader) (*Eop_table, error) {
+	e := &Eop_table{}
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		line_no++
+
+		if len(line) < 68 || strings.TrimSpace(line[58:68]) == "" {
+			continue
+		}
+
+		mjd, err := strconv.ParseFloat(strings.TrimSpace(line[7:15]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("iers: finals line %d: bad MJD: %w", line_no, err)
+		}
+		dut1, err := strconv.ParseFloat(strings.TrimSpace(line[58:68]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("iers: finals line %d: bad UT1-UTC: %w", line_no, err)
+		}
+
+		e.add(mjd, dut1, line[57] == 'P')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(e.Mjd) == 0 {
+		return nil, fmt.Errorf("iers: no UT1-UTC values found in finals data")
+	}
+
+	return e, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse the UT1-UTC tables of a weekly IERS Bulletin A
+//
+// Both tables are read: the combined EOP series (year month day MJD x err y err UT1-UTC err)
+// and the predictions (year month day MJD x y UT1-UTC). A line is only accepted when its MJD
+// matches its calendar date, so the other numeric lines of the bulletin are ignored.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_bulletin_a(r io.Reader) (*Eop_table, error) {
+	e := &Eop_table{}
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		line_no++
+
+		var dut1_field int
+		var predicted bool
+
+		switch len(fields) {
+		case 10:
+			dut1_field = 8
+		case 7:
+			dut1_field, predicted = 6, true
+		default:
+			continue
+		}
+
+		var ymdm [4]int
+		ok := true
+		for i := range ymdm {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				ok = false
+				break
+			}
+			ymdm[i] = v
+		}
+		if !ok || ymdm[1] < 1 || ymdm[1] > 12 || ymdm[2] < 1 || ymdm[2] > 31 {
+			continue
+		}
+
+		// the combined series uses two digit years
+		years := []int{ymdm[0]}
+		if ymdm[0] < 100 {
+			years = []int{2000 + ymdm[0], 1900 + ymdm[0]}
+		}
+		ok = false
+		for _, year := range years {
+			date := time.Date(year, time.Month(ymdm[1]), ymdm[2], 0, 0, 0, 0, time.UTC)
+			ok = ok || astrotime.Mjd(date) == float64(ymdm[3])
+		}
+		if !ok {
+			continue
+		}
+
+		dut1, err := strconv.ParseFloat(fields[dut1_field], 64)
+		if err != nil {
+			return nil, fmt.Errorf("iers: Bulletin A line %d: bad UT1-UTC: %w", line_no, err)
+		}
+
+		e.add(float64(ymdm[3]), dut1, predicted)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(e.Mjd) == 0 {
+		return nil, fmt.Errorf("iers: no UT1-UTC values found in Bulletin A")
+	}
+
+	return e, nil
+}
@@ -0,0 +1,106 @@
+// Package iers reads IERS Earth orientation and leap second files.
+//
+// Supported inputs (as distributed by the IERS Rapid Service/Prediction Center
+// and the IERS Earth Orientation Center):
+//
+//	finals2000A.all / finals2000A.data / finals.daily   (Parse_finals)
+//	Bulletin A (weekly text bulletin)                   (Parse_bulletin_a)
+//	Bulletin C (leap second announcement)               (Parse_bulletin_c)
+//	leap-seconds.list (NTP based leap second list)      (Parse_leap_seconds)
+//
+// The resulting tables give UT1-UTC (DUT1) and TAI-UTC for any instant they
+// cover, so that gosolar's Delta_ut1 and
+//
+//	Delta_t = 32.184 + (TAI-UTC) - DUT1
+//
+// can be filled in automatically (see Data).
+package iers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Spectrafy/gosolar/astrotime"
+)
+
+const (
+	MJD_UNIX_EPOCH = 40587.0          // Modified Julian Date of 1970-01-01 00:00 UTC
+	TT_TAI         = astrotime.TT_TAI // TT - TAI [seconds]
+)
+
+// ErrOutOfRange is matched (with errors.Is) by every lookup outside the loaded data.
+var ErrOutOfRange = errors.New("iers: date outside loaded data")
+
+// Range_error reports a lookup for an instant that the loaded table does not cover.
+type Range_error struct {
+	Table string    // "UT1-UTC" or "TAI-UTC"
+	Time  time.Time // requested instant
+	First time.Time // first instant covered by the table
+	Last  time.Time // last instant covered by the table
+	Gap   bool      // Time falls in a gap of the table between First and Last
+}
+
+func (e *Range_error) Error() string {
+	if e.Gap {
+		return fmt.Sprintf("iers: no %s data for %s (gap in loaded data from %s to %s)", e.Table,
+			e.Time.UTC().Format(time.RFC3339), e.First.UTC().Format("2006-01-02"), e.Last.UTC().Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("iers: no %s data for %s (loaded data covers %s to %s)", e.Table,
+		e.Time.UTC().Format(time.RFC3339), e.First.UTC().Format("2006-01-02"), e.Last.UTC().Format("2006-01-02"))
+}
+
+func (e *Range_error) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+// Modified Julian Date (UTC) of an instant, as astrotime.Mjd
+func Mjd(t time.Time) float64 {
+	return astrotime.Mjd(t)
+}
+
+// UTC instant of a Modified Julian Date, as astrotime.Mjd_time
+func Mjd_time(mjd float64) time.Time {
+	return astrotime.Mjd_time(mjd)
+}
+
+// Data combines Earth orientation and leap second tables. It satisfies the
+// Spa_earth_orientation interface of package gosolar.
+type Data struct {
+	Eop  *Eop_table  // UT1-UTC values
+	Leap *Leap_table // TAI-UTC steps
+}
+
+// UT1-UTC [seconds] at t
+func (d *Data) DUT1(t time.Time) (float64, error) {
+	if d.Eop == nil {
+		return 0, errors.New("iers: no UT1-UTC table loaded")
+	}
+
+	return d.Eop.DUT1(t)
+}
+
+// TAI-UTC [seconds] at t
+func (d *Data) TAI_UTC(t time.Time) (float64, error) {
+	if d.Leap == nil {
+		return 0, errors.New("iers: no leap second table loaded")
+	}
+
+	return d.Leap.TAI_UTC(t)
+}
+
+// TT-UT1 [seconds] at t, i.e. 32.184 + (TAI-UTC) - DUT1
+func (d *Data) Delta_t(t time.Time) (float64, error) {
+	dut1, err := d.DUT1(t)
+	if err != nil {
+		return 0, err
+	}
+
+	tai_utc, err := d.TAI_UTC(t)
+	if err != nil {
+		return 0, err
+	}
+
+	return TT_TAI + tai_utc - dut1, nil
+}
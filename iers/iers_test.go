@@ -0,0 +1,282 @@
+package iers
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Spectrafy/gosolar/astrotime"
+)
+
+func open_testdata(t *testing.T, name string) *os.File {
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestMjd(t *testing.T) {
+	for _, tt := range []struct {
+		t   time.Time
+		mjd float64
+	}{
+		{date(1970, 1, 1, 0), MJD_UNIX_EPOCH},
+		{date(1858, 11, 17, 0), 0},
+		{date(2017, 1, 1, 12), 57754.5},
+	} {
+		if got := Mjd(tt.t); got != tt.mjd {
+			t.Errorf("Mjd(%v) = %v, want %v", tt.t, got, tt.mjd)
+		}
+		if got := Mjd_time(tt.mjd); !got.Equal(tt.t) {
+			t.Errorf("Mjd_time(%v) = %v, want %v", tt.mjd, got, tt.t)
+		}
+	}
+}
+
+func TestParse_finals(t *testing.T) {
+	e, err := Parse_finals(open_testdata(t, "finals2000A.sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last line has no UT1-UTC value (end of the prediction span)
+	if len(e.Mjd) != 7 || e.Mjd[0] != 57750 || e.Mjd[6] != 57756 {
+		t.Fatalf("MJD %v, want 57750 to 57756", e.Mjd)
+	}
+	if e.Dut1[3] != -0.406905 || e.Dut1[4] != 0.592302 {
+		t.Errorf("UT1-UTC of 2016-12-31 and 2017-01-01 = %v, %v", e.Dut1[3], e.Dut1[4])
+	}
+	if e.Predicted[4] || !e.Predicted[5] {
+		t.Errorf("predicted flags %v, want predictions from 2017-01-02", e.Predicted)
+	}
+
+	_, err = Parse_finals(open_testdata(t, "finals2000A_bad_mjd.sample"))
+	if err == nil || !strings.Contains(err.Error(), "line 3: bad MJD") {
+		t.Errorf("bad MJD: error %v", err)
+	}
+
+	if _, err := Parse_finals(strings.NewReader("not a finals file\n")); err == nil {
+		t.Errorf("file without values: no error")
+	}
+}
+
+func TestEop_table_DUT1(t *testing.T) {
+	e, err := Parse_finals(open_testdata(t, "finals2000A.sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		t    time.Time
+		dut1 float64
+	}{
+		{"daily value", date(2016, 12, 29, 0), -0.405327},
+		{"interpolated", date(2016, 12, 29, 12), (-0.405327 - 0.406139) / 2},
+		{"quarter day", date(2016, 12, 28, 6), -0.40456 + (-0.405327+0.40456)/4},
+		// the leap second at the end of 2016: the day before keeps the pre-leap trend
+		{"day of the leap second", date(2016, 12, 31, 12), -0.406905 + (0.592302-1+0.406905)/2},
+		{"end of the day of the leap second", date(2016, 12, 31, 23).Add(3599 * time.Second),
+			-0.406905 + (0.592302-1+0.406905)*86399/86400},
+		{"after the leap second", date(2017, 1, 1, 0), 0.592302},
+		{"prediction", date(2017, 1, 2, 12), (0.59156 + 0.59081) / 2},
+	} {
+		got, err := e.DUT1(tt.t)
+		if err != nil || math.Abs(got-tt.dut1) > 1e-12 {
+			t.Errorf("%s: DUT1(%v) = %v, %v; want %v", tt.name, tt.t, got, err, tt.dut1)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		t    time.Time
+		gap  bool
+	}{
+		{"before the table", date(2016, 12, 27, 23), false},
+		{"after the table", date(2017, 1, 3, 1), false},
+	} {
+		_, err := e.DUT1(tt.t)
+		var range_err *Range_error
+		if !errors.Is(err, ErrOutOfRange) || !errors.As(err, &range_err) {
+			t.Errorf("%s: error %v, want a *Range_error matching ErrOutOfRange", tt.name, err)
+			continue
+		}
+		if range_err.Gap != tt.gap || !range_err.First.Equal(date(2016, 12, 28, 0)) || !range_err.Last.Equal(date(2017, 1, 3, 0)) {
+			t.Errorf("%s: %+v", tt.name, range_err)
+		}
+	}
+
+	// a gap longer than MAX_EOP_GAP is not interpolated
+	gap := &Eop_table{}
+	gap.add(57700, 0.1, false)
+	gap.add(57720, 0.2, false)
+	_, err = gap.DUT1(astrotime.Mjd_time(57710))
+	var range_err *Range_error
+	if !errors.As(err, &range_err) || !range_err.Gap {
+		t.Errorf("gap: error %v, want a gap *Range_error", err)
+	}
+	if _, err := (&Eop_table{}).DUT1(date(2017, 1, 1, 0)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("empty table: error %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestParse_bulletin_a(t *testing.T) {
+	e, err := Parse_bulletin_a(open_testdata(t, "bulletin_a.sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		mjd       float64
+		dut1      float64
+		predicted bool
+	}{
+		{57752, -0.406139, false},
+		{57753, -0.406905, false},
+		{57754, 0.592302, false},
+		{57755, 0.591571, false},
+		{57762, 0.586000, false}, // the observation wins over the prediction of the same day
+		{57763, 0.58490, true},
+		{57764, 0.58430, true},
+	}
+	if len(e.Mjd) != len(want) {
+		t.Fatalf("MJD %v, want %d values", e.Mjd, len(want))
+	}
+	for i, w := range want {
+		if e.Mjd[i] != w.mjd || e.Dut1[i] != w.dut1 || e.Predicted[i] != w.predicted {
+			t.Errorf("value %d: %v %v %v, want %+v", i, e.Mjd[i], e.Dut1[i], e.Predicted[i], w)
+		}
+	}
+
+	if _, err := Parse_bulletin_a(strings.NewReader("1 2 3 4 5 6 7\n")); err == nil {
+		t.Errorf("bulletin without values: no error")
+	}
+}
+
+func TestEop_table_merge(t *testing.T) {
+	observed := &Eop_table{}
+	observed.add(57755, 0.5916, false)
+	predicted := &Eop_table{}
+	predicted.add(57755, 0.5900, true)
+	predicted.add(57756, 0.5890, true)
+
+	observed.Merge(predicted)
+	if len(observed.Mjd) != 2 || observed.Dut1[0] != 0.5916 || observed.Predicted[0] || !observed.Predicted[1] {
+		t.Errorf("prediction merged into observations: %+v", observed)
+	}
+
+	predicted.Merge(&Eop_table{Mjd: []float64{57756}, Dut1: []float64{0.5891}, Predicted: []bool{false}})
+	if predicted.Dut1[1] != 0.5891 || predicted.Predicted[1] {
+		t.Errorf("observation merged into predictions: %+v", predicted)
+	}
+}
+
+func TestParse_leap_seconds(t *testing.T) {
+	l, err := Parse_leap_seconds(open_testdata(t, "leap-seconds.sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := date(2025, 6, 28, 0); !l.Expires.Equal(want) {
+		t.Errorf("Expires %v, want %v", l.Expires, want)
+	}
+
+	for _, tt := range []struct {
+		t       time.Time
+		tai_utc float64
+	}{
+		{date(1972, 1, 1, 0), 10},
+		{date(1972, 6, 30, 23).Add(3599 * time.Second), 10},
+		{date(1972, 7, 1, 0), 11},
+		{date(2000, 1, 1, 0), 12}, // the sample only lists some of the steps
+		{date(2016, 12, 31, 23).Add(3599 * time.Second), 36},
+		{date(2017, 1, 1, 0), 37},
+		{date(2025, 6, 28, 0), 37},
+	} {
+		if got, err := l.TAI_UTC(tt.t); err != nil || got != tt.tai_utc {
+			t.Errorf("TAI_UTC(%v) = %v, %v; want %v", tt.t, got, err, tt.tai_utc)
+		}
+	}
+
+	for _, tm := range []time.Time{date(1971, 12, 31, 23), date(2025, 6, 28, 1)} {
+		if _, err := l.TAI_UTC(tm); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("TAI_UTC(%v): error %v, want ErrOutOfRange", tm, err)
+		}
+	}
+
+	_, err = Parse_leap_seconds(open_testdata(t, "leap-seconds_bad.sample"))
+	if err == nil || !strings.Contains(err.Error(), "line 2: bad TAI-UTC") {
+		t.Errorf("bad TAI-UTC: error %v", err)
+	}
+	for _, text := range []string{"#@ soon\n2272060800 10\n", "2272060800\n", "# comments only\n"} {
+		if _, err := Parse_leap_seconds(strings.NewReader(text)); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
+
+func TestParse_bulletin_c(t *testing.T) {
+	c, err := Parse_bulletin_c(open_testdata(t, "bulletin_c.sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Mjd) != 1 || c.Mjd[0] != 57754 || c.Tai_utc[0] != 37 {
+		t.Fatalf("Bulletin C step %v %v, want 37 s from MJD 57754", c.Mjd, c.Tai_utc)
+	}
+
+	// a Bulletin C brings a list that stopped before the leap second up to date
+	l := Builtin_leap_table()
+	l.Mjd, l.Tai_utc = l.Mjd[:len(l.Mjd)-1], l.Tai_utc[:len(l.Tai_utc)-1]
+	l.Merge(c)
+	if got, _ := l.TAI_UTC(date(2017, 1, 1, 0)); got != 37 {
+		t.Errorf("merged TAI-UTC on 2017-01-01 = %v, want 37", got)
+	}
+
+	for _, text := range []string{
+		"from 2017 January 1, 0h UTC, to 2018 January 1 0h UTC : UTC-TAI = - 37s",
+		"from 2017 Janvier 1, 0h UTC, until further notice : UTC-TAI = - 37s",
+	} {
+		if _, err := Parse_bulletin_c(strings.NewReader(text)); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
+
+func TestData(t *testing.T) {
+	eop, err := Parse_finals(open_testdata(t, "finals2000A.sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Data{Eop: eop, Leap: Builtin_leap_table()}
+
+	for _, tt := range []struct {
+		t       time.Time
+		delta_t float64
+	}{
+		{date(2016, 12, 31, 0), 32.184 + 36 + 0.406905},
+		{date(2017, 1, 1, 0), 32.184 + 37 - 0.592302},
+	} {
+		if got, err := d.Delta_t(tt.t); err != nil || math.Abs(got-tt.delta_t) > 1e-12 {
+			t.Errorf("Delta_t(%v) = %v, %v; want %v", tt.t, got, err, tt.delta_t)
+		}
+	}
+
+	if _, err := d.Delta_t(date(2020, 1, 1, 0)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Delta_t after the EOP table: error %v, want ErrOutOfRange", err)
+	}
+	if _, err := (&Data{Leap: d.Leap}).Delta_t(date(2017, 1, 1, 0)); err == nil {
+		t.Errorf("Delta_t without an EOP table: no error")
+	}
+	if _, err := (&Data{Eop: eop}).TAI_UTC(date(2017, 1, 1, 0)); err == nil {
+		t.Errorf("TAI_UTC without a leap second table: no error")
+	}
+}
@@ -0,0 +1,181 @@
+package iers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Spectrafy/gosolar/astrotime"
+)
+
+const (
+	MJD_NTP_EPOCH = 15020.0 // Modified Julian Date of 1900-01-01 00:00 UTC (NTP epoch)
+)
+
+// Leap_table holds the TAI-UTC steps of UTC since 1972, sorted by date.
+type Leap_table struct {
+	Mjd     []float64 // Modified Julian Date (0h UTC) from which each value applies
+	Tai_utc []float64 // TAI-UTC [seconds]
+	Expires time.Time // end of validity of the list (zero if unknown)
+}
+
+func (l *Leap_table) add(mjd, tai_utc float64) {
+	i := sort.SearchFloat64s(l.Mjd, mjd)
+
+	if i < len(l.Mjd) && l.Mjd[i] == mjd {
+		l.Tai_utc[i] = tai_utc
+		return
+	}
+
+	l.Mjd = append(l.Mjd, 0)
+	l.Tai_utc = append(l.Tai_utc, 0)
+	copy(l.Mjd[i+1:], l.Mjd[i:])
+	copy(l.Tai_utc[i+1:], l.Tai_utc[i:])
+	l.Mjd[i], l.Tai_utc[i] = mjd, tai_utc
+}
+
+// Merge adds the steps of o to l and keeps the later of the two expiry dates.
+func (l *Leap_table) Merge(o *Leap_table) {
+	for i := range o.Mjd {
+		l.add(o.Mjd[i], o.Tai_utc[i])
+	}
+	if o.Expires.After(l.Expires) {
+		l.Expires = o.Expires
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// TAI-UTC [seconds] at t
+// Note: Fails before 1972-01-01 (start of the integer-second UTC) and after Expires, if set
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (l *Leap_table) TAI_UTC(t time.Time) (float64, error) {
+	n := len(l.Mjd)
+	mjd := astrotime.Mjd(t)
+
+	if n == 0 || mjd < l.Mjd[0] || (!l.Expires.IsZero() && t.After(l.Expires)) {
+		err := &Range_error{Table: "TAI-UTC", Time: t, Last: l.Expires}
+		if n > 0 {
+			err.First = astrotime.Mjd_time(l.Mjd[0])
+			if l.Expires.IsZero() {
+				err.Last = astrotime.Mjd_time(l.Mjd[n-1])
+			}
+		}
+		return 0, err
+	}
+
+	i := sort.Search(n, func(i int) bool { return l.Mjd[i] > mjd })
+
+	return l.Tai_utc[i-1], nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse a leap-seconds.list file
+//
+// Data lines hold the NTP time stamp (seconds since 1900-01-01 0h UTC) from which a TAI-UTC
+// value applies, followed by that value; the "#@" line holds the expiry time stamp. Other
+// comment lines (starting with '#') are ignored.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_leap_seconds(r io.Reader) (*Leap_table, error) {
+	l := &Leap_table{}
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line_no++
+
+		if strings.HasPrefix(line, "#@") {
+			ntp, err := strconv.ParseInt(strings.TrimSpace(line[2:]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("iers: leap-seconds.list line %d: bad expiry: %w", line_no, err)
+			}
+			l.Expires = astrotime.Mjd_time(MJD_NTP_EPOCH + float64(ntp)/86400.0)
+			continue
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("iers: leap-seconds.list line %d: expected NTP time and TAI-UTC", line_no)
+		}
+
+		ntp, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("iers: leap-seconds.list line %d: bad NTP time: %w", line_no, err)
+		}
+		tai_utc, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("iers: leap-seconds.list line %d: bad TAI-UTC: %w", line_no, err)
+		}
+
+		l.add(MJD_NTP_EPOCH+float64(ntp/86400), tai_utc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(l.Mjd) == 0 {
+		return nil, fmt.Errorf("iers: no TAI-UTC values found in leap-seconds.list")
+	}
+
+	return l, nil
+}
+
+var bulletin_c_statement = regexp.MustCompile(`from\s+(\d{4})\s+([A-Za-z]+)\s+(\d{1,2}),?\s+0h\s+UTC,?\s+` +
+	`until\s+further\s+notice\s*:\s*UTC\s*-\s*TAI\s*=\s*([-+]?)\s*(\d+)\s*s`)
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse an IERS Bulletin C
+//
+// Reads the statement "from YYYY Month D, 0h UTC, until further notice : UTC-TAI = - N s" and
+// returns a one-step table meant to be merged into a full list (see Leap_table.Merge). The
+// statements of earlier periods ("from ... to ...") are ignored.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_bulletin_c(r io.Reader) (*Leap_table, error) {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := bulletin_c_statement.FindSubmatch(text)
+	if m == nil {
+		return nil, fmt.Errorf("iers: no \"from ..., until further notice : UTC-TAI = ... s\" statement found in Bulletin C")
+	}
+
+	date, err := time.Parse("2006 January 2", fmt.Sprintf("%s %s %s", m[1], m[2], m[3]))
+	if err != nil {
+		return nil, fmt.Errorf("iers: Bulletin C: bad date: %w", err)
+	}
+	utc_tai, _ := strconv.Atoi(string(m[5]))
+	if string(m[4]) != "+" {
+		utc_tai = -utc_tai
+	}
+
+	l := &Leap_table{}
+	l.add(astrotime.Mjd(date), float64(-utc_tai))
+
+	return l, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Leap second table of astrotime.Builtin_leap_steps (all steps up to TAI-UTC = 37 s on
+// 2017-01-01)
+// Note: It has no expiry; load a current leap-seconds.list to know about later leap seconds
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Builtin_leap_table() *Leap_table {
+	l := &Leap_table{}
+	for _, s := range astrotime.Builtin_leap_steps() {
+		l.add(astrotime.Mjd(s.Utc), s.Tai_utc)
+	}
+
+	return l
+}
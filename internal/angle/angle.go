@@ -0,0 +1,36 @@
+// Package angle holds the degree and radian helpers shared by gosolar, astrotime and
+// coordinates.
+package angle
+
+import (
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Angle in degrees of an angle in radians
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Rad2deg(radians float64) float64 {
+	return (180.0 / math.Pi) * radians
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Angle in radians of an angle in degrees
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Deg2rad(degrees float64) float64 {
+	return (math.Pi / 180.0) * degrees
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Angle reduced to 0 to 360 degrees, as in NREL's spa.c
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Limit_degrees(degrees float64) float64 {
+	var limited float64
+
+	degrees /= 360.0
+	limited = 360.0 * (degrees - math.Floor(degrees))
+	if limited < 0 {
+		limited += 360.0
+	}
+
+	return limited
+}
@@ -0,0 +1,260 @@
+package gosolar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	PPA_LIGHT_TIME_ITERATIONS = 3            //light-time corrections applied to the planet position
+	LIGHT_TIME_AU             = 0.0057755183 //light time for one AU [days]
+)
+
+// Ppa_rts holds the rise, transit and set of a planet on one local calendar day.
+type Ppa_rts struct {
+	State   int       // SPA_RTS_NORMAL, or SPA_RTS_POLAR_DAY/SPA_RTS_POLAR_NIGHT when the planet stays up/down
+	Rise    time.Time // local rise, zero if the planet does not rise that day
+	Transit time.Time // local (upper) transit, zero if there is none that day
+	Set     time.Time // local set, zero if the planet does not set that day
+}
+
+// Ppa_result holds the position of a planet (Planet Position Algorithm) for one instant.
+type Ppa_result struct {
+	//-----------------Intermediate OUTPUT VALUES--------------------
+
+	L   float64 //planet heliocentric longitude at the light-time corrected instant [degrees]
+	B   float64 //planet heliocentric latitude at the light-time corrected instant [degrees]
+	R   float64 //planet radius vector at the light-time corrected instant [Astronomical Units, AU]
+	Tau float64 //light time from the planet to earth [days]
+
+	Cap_delta float64 //distance from earth to planet [Astronomical Units, AU]
+	Lamda     float64 //apparent geocentric longitude [degrees]
+	Beta      float64 //apparent geocentric latitude [degrees]
+	Alpha     float64 //geocentric planet right ascension [degrees]
+	Delta     float64 //geocentric planet declination [degrees]
+
+	H           float64 //observer hour angle [degrees]
+	Xi          float64 //planet equatorial horizontal parallax [degrees]
+	Del_alpha   float64 //planet right ascension parallax [degrees]
+	Delta_prime float64 //topocentric planet declination [degrees]
+	Alpha_prime float64 //topocentric planet right ascension [degrees]
+	H_prime     float64 //topocentric local hour angle [degrees]
+
+	E0    float64 //topocentric elevation angle (uncorrected) [degrees]
+	Del_e float64 //atmospheric refraction correction [degrees]
+	E     float64 //topocentric elevation angle (corrected) [degrees]
+
+	//---------------------Final OUTPUT VALUES------------------------
+
+	Zenith        float64 //topocentric zenith angle [degrees]
+	Azimuth_astro float64 //topocentric azimuth angle (westward from south) [for astronomers]
+	Azimuth       float64 //topocentric azimuth angle (eastward from north) [for navigators and solar radiation]
+}
+
+// geocentric rectangular ecliptic coordinates of a planet from heliocentric planet and earth [AU]
+func planet_geocentric_xyz(l, b, r, l0, b0, r0 float64, x, y, z *float64) {
+	l_rad, b_rad := deg2rad(l), deg2rad(b)
+	l0_rad, b0_rad := deg2rad(l0), deg2rad(b0)
+
+	*x = r*math.Cos(b_rad)*math.Cos(l_rad) - r0*math.Cos(b0_rad)*math.Cos(l0_rad)
+	*y = r*math.Cos(b_rad)*math.Sin(l_rad) - r0*math.Cos(b0_rad)*math.Sin(l0_rad)
+	*z = r*math.Sin(b_rad) - r0*math.Sin(b0_rad)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Conversion of the VSOP87 longitude and latitude to the FK5 system (Meeus 32.3) [degrees]
+////////////////////////////////////////////////////////////////////////////////////////////////
+func fk5_correction(jce, lamda, beta float64, del_lamda, del_beta *float64) {
+	lamda_prime := deg2rad(lamda - 1.397*jce - 0.00031*jce*jce)
+
+	*del_lamda = (-0.09033 + 0.03916*(math.Cos(lamda_prime)+math.Sin(lamda_prime))*
+		math.Tan(deg2rad(beta))) / 3600.0
+	*del_beta = 0.03916 * (math.Cos(lamda_prime) - math.Sin(lamda_prime)) / 3600.0
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Annual aberration in ecliptic longitude and latitude (Meeus 23.2) [degrees]
+// Note: theta is the geometric sun longitude [degrees]
+////////////////////////////////////////////////////////////////////////////////////////////////
+func ecliptic_aberration(jce, theta, lamda, beta float64, del_lamda, del_beta *float64) {
+	kappa := 20.49552 / 3600.0
+	e := 0.016708634 - jce*(0.000042037+0.0000001267*jce)
+	pi := deg2rad(102.93735 + jce*(1.71946+0.00046*jce))
+	sun := deg2rad(theta)
+	lamda_rad := deg2rad(lamda)
+	beta_rad := deg2rad(beta)
+
+	*del_lamda = (-kappa*math.Cos(sun-lamda_rad) + e*kappa*math.Cos(pi-lamda_rad)) / math.Cos(beta_rad)
+	*del_beta = -kappa * math.Sin(beta_rad) * (math.Sin(sun-lamda_rad) - e*math.Sin(pi-lamda_rad))
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the apparent geocentric planet position (Meeus, chapter 33)
+//
+// The planet is taken where it was when the light now arriving left it (light time) and its
+// longitude is then corrected for FK5, nutation and annual aberration like the sun's.
+// Note: jme, jce, L, B, R, theta, Del_psi and Epsilon must be already calculated
+////////////////////////////////////////////////////////////////////////////////////////////////
+func calculate_geocentric_planet_right_ascension_and_declination(spa *Spa_data, planet *Vsop87_series,
+	ppa *Ppa_result) {
+	var x, y, z, del_lamda, del_beta, aber_lamda, aber_beta float64
+	var i int
+
+	ppa.Tau = 0
+	for i = 0; i < PPA_LIGHT_TIME_ITERATIONS; i++ {
+		planet.heliocentric(spa.jme-ppa.Tau/365250.0, &ppa.L, &ppa.B, &ppa.R)
+		planet_geocentric_xyz(ppa.L, ppa.B, ppa.R, spa.L, spa.B, spa.R, &x, &y, &z)
+		ppa.Cap_delta = math.Sqrt(x*x + y*y + z*z)
+		ppa.Tau = LIGHT_TIME_AU * ppa.Cap_delta
+	}
+
+	lamda := limit_degrees(rad2deg(math.Atan2(y, x)))
+	beta := rad2deg(math.Atan2(z, math.Sqrt(x*x+y*y)))
+
+	fk5_correction(spa.jce, lamda, beta, &del_lamda, &del_beta)
+	ecliptic_aberration(spa.jce, spa.theta, lamda, beta, &aber_lamda, &aber_beta)
+
+	ppa.Lamda = limit_degrees(lamda + del_lamda + spa.Del_psi + aber_lamda)
+	ppa.Beta = beta + del_beta + aber_beta
+
+	ppa.Alpha = geocentric_right_ascension(ppa.Lamda, spa.Epsilon, ppa.Beta)
+	ppa.Delta = geocentric_declination(ppa.Beta, spa.Epsilon, ppa.Lamda)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the topocentric planet position for the instant in structure
+// Note: jme, jce, nu and the geocentric sun position must be already calculated
+///////////////////////////////////////////////////////////////////////////////////////////
+func calculate_ppa(spa *Spa_data, planet *Vsop87_series, ppa *Ppa_result) {
+	calculate_geocentric_planet_right_ascension_and_declination(spa, planet, ppa)
+
+	ppa.H = observer_hour_angle(spa.nu, spa.Longitude, ppa.Alpha)
+	ppa.Xi = sun_equatorial_horizontal_parallax(ppa.Cap_delta)
+
+	right_ascension_parallax_and_topocentric_dec(spa.Latitude, spa.Elevation, ppa.Xi,
+		ppa.H, ppa.Delta, &(ppa.Del_alpha), &(ppa.Delta_prime))
+
+	ppa.Alpha_prime = topocentric_right_ascension(ppa.Alpha, ppa.Del_alpha)
+	ppa.H_prime = topocentric_local_hour_angle(ppa.H, ppa.Del_alpha)
+
+	ppa.E0 = topocentric_elevation_angle(spa.Latitude, ppa.Delta_prime, ppa.H_prime)
+	ppa.Del_e = refraction_correction(spa, ppa.E0)
+	ppa.E = topocentric_elevation_angle_corrected(ppa.E0, ppa.Del_e)
+
+	ppa.Zenith = topocentric_zenith_angle(ppa.E)
+	ppa.Azimuth_astro = topocentric_azimuth_angle_astro(ppa.H_prime, spa.Latitude, ppa.Delta_prime)
+	ppa.Azimuth = topocentric_azimuth_angle(ppa.Azimuth_astro)
+}
+
+func ppa_check_planet(planet *Vsop87_series) error {
+	if planet == nil || len(planet.L) == 0 || len(planet.B) == 0 || len(planet.R) == 0 {
+		return fmt.Errorf("%w: planet series without L, B and R terms", ErrInvalidInput)
+	}
+	if planet.Name == Vsop87_earth.Name {
+		return fmt.Errorf("%w: the earth has no geocentric position", ErrInvalidInput)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the position of a planet for the inputs in structure
+//
+// planet is the VSOP87D series of the planet (see Parse_vsop87); the earth is SPA's own.
+// The date, time and observer inputs of in are used as for Spa_compute (Slope, Azm_rotation,
+// Function, Algorithm and Precision are ignored: the planet is seen from the full SPA earth).
+///////////////////////////////////////////////////////////////////////////////////////////
+func Ppa_compute(in Spa_data, planet *Vsop87_series) (Ppa_result, error) {
+	var ppa Ppa_result
+
+	if err := ppa_check_planet(planet); err != nil {
+		return ppa, err
+	}
+
+	in.Function, in.Algorithm, in.Precision = SPA_ZA, nil, SPA_PRECISION_FULL
+	if err := Spa_validate(&in); err != nil {
+		return ppa, err
+	}
+	if err := apply_earth_orientation(&in); err != nil {
+		return ppa, err
+	}
+
+	calculate_julian_day(&in)
+	calculate_geocentric_sun_right_ascension_and_declination(&in)
+	calculate_ppa(&in, planet, &ppa)
+
+	return ppa, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the position of a planet for the instant t
+///////////////////////////////////////////////////////////////////////////////////////////
+func Ppa_compute_time(t time.Time, in Spa_data, planet *Vsop87_series) (Ppa_result, error) {
+	spa_set_time(&in, t)
+
+	return Ppa_compute(in, planet)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Calculate the three-day geocentric planet ephemeris for the date in structure (0h UT)
+//
+// Rise and set are when the apparent planet is at -depression, the geocentric center
+// at xi - depression (the parallax xi lowers the topocentric planet).
+////////////////////////////////////////////////////////////////////////
+func calculate_planet_rts_day(spa *Spa_data, planet *Vsop87_series, depression float64, day *body_rts_day) {
+	var planet_rts Spa_data
+	var ppa Ppa_result
+	var i int
+
+	planet_rts = *spa
+
+	planet_rts.Hour, planet_rts.Minute, planet_rts.Second = 0, 0, 0
+	planet_rts.Delta_ut1, planet_rts.Timezone = 0.0, 0.0
+
+	planet_rts.Jd = julian_day_calendar(planet_rts.Year, planet_rts.Month, planet_rts.Day, planet_rts.Hour,
+		planet_rts.Minute, planet_rts.Second, planet_rts.Delta_ut1, planet_rts.Timezone, planet_rts.Calendar)
+
+	calculate_geocentric_sun_right_ascension_and_declination(&planet_rts)
+	day.nu = planet_rts.nu
+
+	planet_rts.Delta_t = 0
+	planet_rts.Jd--
+	for i = 0; i < JD_COUNT; i++ {
+		calculate_geocentric_sun_right_ascension_and_declination(&planet_rts)
+		calculate_geocentric_planet_right_ascension_and_declination(&planet_rts, planet, &ppa)
+		day.alpha[i] = ppa.Alpha
+		day.delta[i] = ppa.Delta
+		day.h0[i] = sun_equatorial_horizontal_parallax(ppa.Cap_delta) - depression
+		planet_rts.Jd++
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate rise, transit and set of a planet on the local calendar day of t
+//
+// The observer inputs of in are used as for Mpa_moon_rts (Algorithm and Precision are
+// ignored); the planet is a point on the apparent horizon at rise and set. Events are
+// returned in t's location and a zero time means the event does not happen on that local day.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Ppa_planet_rts(t time.Time, in Spa_data, planet *Vsop87_series) (Ppa_rts, error) {
+	var events [SUN_COUNT]time.Time
+
+	if err := ppa_check_planet(planet); err != nil {
+		return Ppa_rts{}, err
+	}
+	in.Algorithm, in.Precision = nil, SPA_PRECISION_FULL
+	if err := spa_rts_inputs(t, &in); err != nil {
+		return Ppa_rts{}, err
+	}
+
+	dip := horizon_dip(&in)
+	depression := dip + horizon_refraction(&in, dip)
+
+	state := body_local_day_events(t, &in, func(spa *Spa_data, day *body_rts_day) {
+		calculate_planet_rts_day(spa, planet, depression, day)
+	}, &events)
+
+	return Ppa_rts{State: state, Rise: events[SUN_RISE], Transit: events[SUN_TRANSIT],
+		Set: events[SUN_SET]}, nil
+}
@@ -0,0 +1,121 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPpa_compute(t *testing.T) {
+	venus := open_vsop87(t, "vsop87d_venus_meeus.txt")
+
+	// Meeus example 33.a: Venus on 1992 December 20 at 0h TD, from the truncated series of
+	// Appendix III; the apparent position is 21h04m41.454s, -18 53' 16.84"
+	in := Spa_data{Year: 1992, Month: 12, Day: 20, Pressure: 1010, Temperature: 10, Atmos_refract: 0.5667}
+
+	var l, b, r float64
+	venus.heliocentric((2448976.5-2451545.0)/365250.0, &l, &b, &r)
+	if math.Abs(l-26.11428) > 5e-6 || math.Abs(b-(-2.62070)) > 5e-6 || math.Abs(r-0.724603) > 5e-7 {
+		t.Errorf("heliocentric %v, %v, %v; Meeus gives 26.11428, -2.62070, 0.724603", l, b, r)
+	}
+
+	p, err := Ppa_compute(in, venus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name      string
+		got       float64
+		want      float64
+		tolerance float64
+	}{
+		{"Tau", p.Tau, 0.0052612, 5e-8},
+		{"L", p.L, 26.10588, 5e-6},
+		{"B", p.B, -2.62102, 5e-6},
+		{"R", p.R, 0.724604, 5e-7},
+		{"Alpha", p.Alpha, 15 * (21 + 4/60.0 + 41.454/3600), 1.0 / 3600},
+		{"Delta", p.Delta, -(18 + 53/60.0 + 16.84/3600), 1.0 / 3600},
+	} {
+		if math.Abs(tt.got-tt.want) > tt.tolerance {
+			t.Errorf("%s = %.7f, Meeus gives %.7f", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// the topocentric angles of an observer in Washington
+	in.Latitude, in.Longitude, in.Elevation = 38.921389, -77.065556, 100
+	p, err = Ppa_compute_time(time.Date(1992, 12, 20, 0, 0, 0, 0, time.UTC), in, venus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(p.Zenith+p.E-90) > 1e-9 || p.Azimuth < 0 || p.Azimuth >= 360 {
+		t.Errorf("zenith %v, elevation %v, azimuth %v", p.Zenith, p.E, p.Azimuth)
+	}
+
+	// the planet needs the nutation and the earth of the full chain, so Algorithm and Precision
+	// are ignored
+	grena := in
+	grena.Algorithm, grena.Precision = Spa_algorithm_grena3{}, SPA_PRECISION_LOW
+	if q, err := Ppa_compute_time(time.Date(1992, 12, 20, 0, 0, 0, 0, time.UTC), grena, venus); err != nil || q != p {
+		t.Errorf("with Algorithm and Precision: %+v, %v, want %+v", q, err, p)
+	}
+
+	if _, err := Ppa_compute(in, &Vsop87_earth); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("earth: error %v, want ErrInvalidInput", err)
+	}
+	if _, err := Ppa_compute(in, &Vsop87_series{Name: "VENUS"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("empty series: error %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestPpa_planet_rts(t *testing.T) {
+	venus := open_vsop87(t, "vsop87d_venus_meeus.txt")
+
+	// Meeus example 15.a: Venus at Boston on 1988 March 20 sets at 0.12130, rises at 0.51766 and
+	// transits at 0.81980 of the day (UT), for the apparent planet at -0.5667 degrees
+	boston := Spa_data{Latitude: 42.3333, Longitude: -71.0833, Delta_t: 56, Pressure: 1010, Temperature: 10,
+		Atmos_refract: 0.5667}
+	day := time.Date(1988, 3, 20, 0, 0, 0, 0, time.UTC)
+	rts, err := Ppa_planet_rts(day.Add(12*time.Hour), boston, venus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rts.State != SPA_RTS_NORMAL {
+		t.Errorf("Boston: state %d", rts.State)
+	}
+	for _, tt := range []struct {
+		name string
+		got  time.Time
+		m    float64
+	}{
+		{"rise", rts.Rise, 0.51766},
+		{"transit", rts.Transit, 0.81980},
+		{"set", rts.Set, 0.12130},
+	} {
+		want := day.Add(time.Duration(tt.m * 24 * float64(time.Hour)))
+		if d := tt.got.Sub(want); d < -5*time.Second || d > 5*time.Second {
+			t.Errorf("%s at %v, Meeus gives %v", tt.name, tt.got, want)
+		}
+	}
+
+	// at a declination near +18 degrees, Venus stays up at 80 degrees north and down at 80
+	// degrees south
+	for _, tt := range []struct {
+		latitude float64
+		state    int
+	}{
+		{80, SPA_RTS_POLAR_DAY},
+		{-80, SPA_RTS_POLAR_NIGHT},
+	} {
+		in := boston
+		in.Latitude = tt.latitude
+		rts, err := Ppa_planet_rts(day.Add(12*time.Hour), in, venus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rts.State != tt.state || !rts.Rise.IsZero() || !rts.Set.IsZero() {
+			t.Errorf("latitude %v: state %d, rise %v, set %v; want state %d", tt.latitude, rts.State, rts.Rise,
+				rts.Set, tt.state)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package gosolar
+
+import (
+	"math"
+	"time"
+)
+
+/////////////////////////////////////////////
+//  Solar and Moon Position Algorithm (SAMPA)
+//
+//  Moon position from Meeus, "Astronomical Algorithms" (2nd ed., chapter 47), as used by
+//  NREL's SAMPA together with the SPA Earth, nutation, sidereal time, parallax and
+//  refraction calculations; illumination and bright limb from chapter 48.
+/////////////////////////////////////////////
+
+const (
+	MOON_COUNT = 60 //rows of each moon periodic term table
+
+	EARTH_RADIUS_KM = 6378.14     //earth equatorial radius used for the moon parallax [kilometers]
+	AU_KM           = 149597870.7 //astronomical unit [kilometers]
+)
+
+const (
+	TERM_D = iota
+	TERM_M
+	TERM_MPR
+	TERM_F
+	TERM_LB
+	TERM_R
+	TERM_MPA_COUNT
+)
+
+///////////////////////////////////////////////////
+///  Moon's Periodic Terms for Longitude and Distance (Meeus Table 47.A)
+///////////////////////////////////////////////////
+var ML_TERMS = [MOON_COUNT][TERM_MPA_COUNT]float64{
+	{0, 0, 1, 0, 6288774, -20905355},
+	{2, 0, -1, 0, 1274027, -3699111},
+	{2, 0, 0, 0, 658314, -2955968},
+	{0, 0, 2, 0, 213618, -569925},
+	{0, 1, 0, 0, -185116, 48888},
+	{0, 0, 0, 2, -114332, -3149},
+	{2, 0, -2, 0, 58793, 246158},
+	{2, -1, -1, 0, 57066, -152138},
+	{2, 0, 1, 0, 53322, -170733},
+	{2, -1, 0, 0, 45758, -204586},
+	{0, 1, -1, 0, -40923, -129620},
+	{1, 0, 0, 0, -34720, 108743},
+	{0, 1, 1, 0, -30383, 104755},
+	{2, 0, 0, -2, 15327, 10321},
+	{0, 0, 1, 2, -12528, 0},
+	{0, 0, 1, -2, 10980, 79661},
+	{4, 0, -1, 0, 10675, -34782},
+	{0, 0, 3, 0, 10034, -23210},
+	{4, 0, -2, 0, 8548, -21636},
+	{2, 1, -1, 0, -7888, 24208},
+	{2, 1, 0, 0, -6766, 30824},
+	{1, 0, -1, 0, -5163, -8379},
+	{1, 1, 0, 0, 4987, -16675},
+	{2, -1, 1, 0, 4036, -12831},
+	{2, 0, 2, 0, 3994, -10445},
+	{4, 0, 0, 0, 3861, -11650},
+	{2, 0, -3, 0, 3665, 14403},
+	{0, 1, -2, 0, -2689, -7003},
+	{2, 0, -1, 2, -2602, 0},
+	{2, -1, -2, 0, 2390, 10056},
+	{1, 0, 1, 0, -2348, 6322},
+	{2, -2, 0, 0, 2236, -9884},
+	{0, 1, 2, 0, -2120, 5751},
+	{0, 2, 0, 0, -2069, 0},
+	{2, -2, -1, 0, 2048, -4950},
+	{2, 0, 1, -2, -1773, 4130},
+	{2, 0, 0, 2, -1595, 0},
+	{4, -1, -1, 0, 1215, -3958},
+	{0, 0, 2, 2, -1110, 0},
+	{3, 0, -1, 0, -892, 3258},
+	{2, 1, 1, 0, -810, 2616},
+	{4, -1, -2, 0, 759, -1897},
+	{0, 2, -1, 0, -713, -2117},
+	{2, 2, -1, 0, -700, 2354},
+	{2, 1, -2, 0, 691, 0},
+	{2, -1, 0, -2, 596, 0},
+	{4, 0, 1, 0, 549, -1423},
+	{0, 0, 4, 0, 537, -1117},
+	{4, -1, 0, 0, 520, -1571},
+	{1, 0, -2, 0, -487, -1739},
+	{2, 1, 0, -2, -399, 0},
+	{0, 0, 2, -2, -381, -4421},
+	{1, 1, 1, 0, 351, 0},
+	{3, 0, -2, 0, -340, 0},
+	{4, 0, -3, 0, 330, 0},
+	{2, -1, 2, 0, 327, 0},
+	{0, 2, 1, 0, -323, 1165},
+	{1, 1, -1, 0, 299, 0},
+	{2, 0, 3, 0, 294, 0},
+	{2, 0, -1, -2, 0, 8752},
+}
+
+///////////////////////////////////////////////////
+///  Moon's Periodic Terms for Latitude (Meeus Table 47.B)
+///////////////////////////////////////////////////
+var MB_TERMS = [MOON_COUNT][TERM_MPA_COUNT]float64{
+	{0, 0, 0, 1, 5128122, 0},
+	{0, 0, 1, 1, 280602, 0},
+	{0, 0, 1, -1, 277693, 0},
+	{2, 0, 0, -1, 173237, 0},
+	{2, 0, -1, 1, 55413, 0},
+	{2, 0, -1, -1, 46271, 0},
+	{2, 0, 0, 1, 32573, 0},
+	{0, 0, 2, 1, 17198, 0},
+	{2, 0, 1, -1, 9266, 0},
+	{0, 0, 2, -1, 8822, 0},
+	{2, -1, 0, -1, 8216, 0},
+	{2, 0, -2, -1, 4324, 0},
+	{2, 0, 1, 1, 4200, 0},
+	{2, 1, 0, -1, -3359, 0},
+	{2, -1, -1, 1, 2463, 0},
+	{2, -1, 0, 1, 2211, 0},
+	{2, -1, -1, -1, 2065, 0},
+	{0, 1, -1, -1, -1870, 0},
+	{4, 0, -1, -1, 1828, 0},
+	{0, 1, 0, 1, -1794, 0},
+	{0, 0, 0, 3, -1749, 0},
+	{0, 1, -1, 1, -1565, 0},
+	{1, 0, 0, 1, -1491, 0},
+	{0, 1, 1, 1, -1475, 0},
+	{0, 1, 1, -1, -1410, 0},
+	{0, 1, 0, -1, -1344, 0},
+	{1, 0, 0, -1, -1335, 0},
+	{0, 0, 3, 1, 1107, 0},
+	{4, 0, 0, -1, 1021, 0},
+	{4, 0, -1, 1, 833, 0},
+	{0, 0, 1, -3, 777, 0},
+	{4, 0, -2, 1, 671, 0},
+	{2, 0, 0, -3, 607, 0},
+	{2, 0, 2, -1, 596, 0},
+	{2, -1, 1, -1, 491, 0},
+	{2, 0, -2, 1, -451, 0},
+	{0, 0, 3, -1, 439, 0},
+	{2, 0, 2, 1, 422, 0},
+	{2, 0, -3, -1, 421, 0},
+	{2, 1, -1, 1, -366, 0},
+	{2, 1, 0, 1, -351, 0},
+	{4, 0, 0, 1, 331, 0},
+	{2, -1, 1, 1, 315, 0},
+	{2, -2, 0, -1, 302, 0},
+	{0, 0, 1, 3, -283, 0},
+	{2, 1, 1, -1, -229, 0},
+	{1, 1, 0, -1, 223, 0},
+	{1, 1, 0, 1, 223, 0},
+	{0, 1, -2, -1, -220, 0},
+	{2, 1, -1, -1, -220, 0},
+	{1, 0, 1, 1, -185, 0},
+	{2, -1, -2, -1, 181, 0},
+	{0, 1, 2, 1, -177, 0},
+	{4, -2, 0, -1, 176, 0},
+	{4, -1, -1, -1, 166, 0},
+	{1, 0, 1, -1, -164, 0},
+	{4, 0, 1, -1, 132, 0},
+	{1, 0, -1, -1, -119, 0},
+	{4, -1, 0, -1, 115, 0},
+	{4, -2, 0, 1, 107, 0},
+}
+
+// Mpa_result holds the moon position and illumination of one SAMPA calculation.
+type Mpa_result struct {
+	//-----------------Intermediate MPA OUTPUT VALUES--------------------
+
+	L_prime float64 //moon mean longitude [degrees]
+	D       float64 //moon mean elongation [degrees]
+	M       float64 //sun mean anomaly [degrees]
+	M_prime float64 //moon mean anomaly [degrees]
+	F       float64 //moon argument of latitude [degrees]
+	L       float64 //term l (sum of the longitude terms) [0.000001 degrees]
+	R       float64 //term r (sum of the distance terms) [0.001 kilometers]
+	B       float64 //term b (sum of the latitude terms) [0.000001 degrees]
+
+	Lamda_prime float64 //moon longitude [degrees]
+	Beta        float64 //moon latitude [degrees]
+	Cap_delta   float64 //distance from earth to moon [kilometers]
+	Pi          float64 //moon equatorial horizontal parallax [degrees]
+	Lamda       float64 //apparent moon longitude [degrees]
+
+	Alpha float64 //geocentric moon right ascension [degrees]
+	Delta float64 //geocentric moon declination [degrees]
+
+	H           float64 //observer hour angle [degrees]
+	Del_alpha   float64 //moon right ascension parallax [degrees]
+	Delta_prime float64 //topocentric moon declination [degrees]
+	Alpha_prime float64 //topocentric moon right ascension [degrees]
+	H_prime     float64 //topocentric local hour angle [degrees]
+
+	E0    float64 //topocentric elevation angle (uncorrected) [degrees]
+	Del_e float64 //atmospheric refraction correction [degrees]
+	E     float64 //topocentric elevation angle (corrected) [degrees]
+
+	//---------------------Final MPA OUTPUT VALUES------------------------
+
+	Zenith        float64 //topocentric zenith angle [degrees]
+	Azimuth_astro float64 //topocentric azimuth angle (westward from south) [for astronomers]
+	Azimuth       float64 //topocentric azimuth angle (eastward from north) [for navigators and solar radiation]
+
+	Elongation           float64 //geocentric elongation of the moon from the sun [degrees]
+	Phase_angle          float64 //selenocentric angle between sun and earth [degrees]
+	Illuminated_fraction float64 //illuminated fraction of the moon disk [0 to 1]
+	Bright_limb          float64 //position angle of the bright limb midpoint (eastward from north) [degrees]
+}
+
+func fourth_order_polynomial(a, b, c, d, e, x float64) float64 {
+	return (((e*x+d)*x+c)*x+b)*x + a
+}
+
+func moon_mean_longitude(jce float64) float64 {
+	return limit_degrees(fourth_order_polynomial(
+		218.3164477, 481267.88123421, -0.0015786, 1.0/538841.0, -1.0/65194000.0, jce))
+}
+
+func moon_mean_elongation(jce float64) float64 {
+	return limit_degrees(fourth_order_polynomial(
+		297.8501921, 445267.1114034, -0.0018819, 1.0/545868.0, -1.0/113065000.0, jce))
+}
+
+func sun_mean_anomaly(jce float64) float64 {
+	return limit_degrees(third_order_polynomial(
+		1.0/24490000.0, -0.0001536, 35999.0502909, 357.5291092, jce))
+}
+
+func moon_mean_anomaly(jce float64) float64 {
+	return limit_degrees(fourth_order_polynomial(
+		134.9633964, 477198.8675055, 0.0087414, 1.0/69699.0, -1.0/14712000.0, jce))
+}
+
+func moon_latitude_argument(jce float64) float64 {
+	return limit_degrees(fourth_order_polynomial(
+		93.2720950, 483202.0175233, -0.0036539, -1.0/3526000.0, 1.0/863310000.0, jce))
+}
+
+func moon_periodic_term_summation(d, m, m_prime, f, jce float64, terms *[MOON_COUNT][TERM_MPA_COUNT]float64,
+	sin_sum, cos_sum *float64) {
+	var i int
+	e := 1.0 - jce*(0.002516+jce*0.0000074)
+
+	*sin_sum = 0
+	if cos_sum != nil {
+		*cos_sum = 0
+	}
+
+	for i = 0; i < MOON_COUNT; i++ {
+		e_mult := math.Pow(e, math.Abs(terms[i][TERM_M]))
+		trig_arg := deg2rad(terms[i][TERM_D]*d + terms[i][TERM_M]*m +
+			terms[i][TERM_F]*f + terms[i][TERM_MPR]*m_prime)
+
+		*sin_sum += e_mult * terms[i][TERM_LB] * math.Sin(trig_arg)
+		if cos_sum != nil {
+			*cos_sum += e_mult * terms[i][TERM_R] * math.Cos(trig_arg)
+		}
+	}
+}
+
+func moon_longitude_and_latitude(jce, l_prime, f, m_prime, l, b float64, lamda_prime, beta *float64) {
+	a1 := 119.75 + 131.849*jce
+	a2 := 53.09 + 479264.290*jce
+	a3 := 313.45 + 481266.484*jce
+	delta_l := 3958*math.Sin(deg2rad(a1)) + 318*math.Sin(deg2rad(a2)) + 1962*math.Sin(deg2rad(l_prime-f))
+	delta_b := -2235*math.Sin(deg2rad(l_prime)) + 175*math.Sin(deg2rad(a1-f)) +
+		127*math.Sin(deg2rad(l_prime-m_prime)) + 382*math.Sin(deg2rad(a3)) +
+		175*math.Sin(deg2rad(a1+f)) - 115*math.Sin(deg2rad(l_prime+m_prime))
+
+	*lamda_prime = limit_degrees(l_prime + (l+delta_l)/1000000)
+	*beta = (b + delta_b) / 1000000
+}
+
+func moon_earth_distance(r float64) float64 {
+	return 385000.56 + r/1000
+}
+
+func moon_equatorial_horiz_parallax(delta float64) float64 {
+	return rad2deg(math.Asin(EARTH_RADIUS_KM / delta))
+}
+
+func apparent_moon_longitude(lamda_prime, del_psi float64) float64 {
+	return lamda_prime + del_psi
+}
+
+func moon_elongation(alpha_sun, delta_sun, alpha, delta float64) float64 {
+	delta_sun_rad := deg2rad(delta_sun)
+	delta_rad := deg2rad(delta)
+
+	return rad2deg(math.Acos(math.Sin(delta_sun_rad)*math.Sin(delta_rad) +
+		math.Cos(delta_sun_rad)*math.Cos(delta_rad)*math.Cos(deg2rad(alpha_sun-alpha))))
+}
+
+func moon_phase_angle(r_sun, psi, cap_delta float64) float64 {
+	psi_rad := deg2rad(psi)
+
+	return rad2deg(math.Atan2(r_sun*math.Sin(psi_rad), cap_delta-r_sun*math.Cos(psi_rad)))
+}
+
+func moon_illuminated_fraction(i float64) float64 {
+	return (1.0 + math.Cos(deg2rad(i))) / 2.0
+}
+
+func moon_bright_limb_angle(alpha_sun, delta_sun, alpha, delta float64) float64 {
+	delta_sun_rad := deg2rad(delta_sun)
+	delta_rad := deg2rad(delta)
+	da_rad := deg2rad(alpha_sun - alpha)
+
+	return limit_degrees(rad2deg(math.Atan2(math.Cos(delta_sun_rad)*math.Sin(da_rad),
+		math.Sin(delta_sun_rad)*math.Cos(delta_rad)-math.Cos(delta_sun_rad)*math.Sin(delta_rad)*math.Cos(da_rad))))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the geocentric moon position for the instant in structure
+// Note: jce, Del_psi and Epsilon must be already calculated
+///////////////////////////////////////////////////////////////////////////////////////////
+func calculate_geocentric_moon_right_ascension_and_declination(spa *Spa_data, mpa *Mpa_result) {
+	mpa.L_prime = moon_mean_longitude(spa.jce)
+	mpa.D = moon_mean_elongation(spa.jce)
+	mpa.M = sun_mean_anomaly(spa.jce)
+	mpa.M_prime = moon_mean_anomaly(spa.jce)
+	mpa.F = moon_latitude_argument(spa.jce)
+
+	moon_periodic_term_summation(mpa.D, mpa.M, mpa.M_prime, mpa.F, spa.jce, &ML_TERMS, &(mpa.L), &(mpa.R))
+	moon_periodic_term_summation(mpa.D, mpa.M, mpa.M_prime, mpa.F, spa.jce, &MB_TERMS, &(mpa.B), nil)
+
+	moon_longitude_and_latitude(spa.jce, mpa.L_prime, mpa.F, mpa.M_prime, mpa.L, mpa.B,
+		&(mpa.Lamda_prime), &(mpa.Beta))
+
+	mpa.Cap_delta = moon_earth_distance(mpa.R)
+	mpa.Pi = moon_equatorial_horiz_parallax(mpa.Cap_delta)
+
+	mpa.Lamda = apparent_moon_longitude(mpa.Lamda_prime, spa.Del_psi)
+
+	mpa.Alpha = geocentric_right_ascension(mpa.Lamda, spa.Epsilon, mpa.Beta)
+	mpa.Delta = geocentric_declination(mpa.Beta, spa.Epsilon, mpa.Lamda)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the moon position and illumination for the instant in structure
+// Note: jce, Del_psi, Epsilon, nu and the geocentric sun position must be already calculated
+///////////////////////////////////////////////////////////////////////////////////////////
+func calculate_mpa(spa *Spa_data, mpa *Mpa_result) {
+	calculate_geocentric_moon_right_ascension_and_declination(spa, mpa)
+
+	mpa.H = observer_hour_angle(spa.nu, spa.Longitude, mpa.Alpha)
+
+	right_ascension_parallax_and_topocentric_dec(spa.Latitude, spa.Elevation, mpa.Pi,
+		mpa.H, mpa.Delta, &(mpa.Del_alpha), &(mpa.Delta_prime))
+
+	mpa.Alpha_prime = topocentric_right_ascension(mpa.Alpha, mpa.Del_alpha)
+	mpa.H_prime = topocentric_local_hour_angle(mpa.H, mpa.Del_alpha)
+
+	mpa.E0 = topocentric_elevation_angle(spa.Latitude, mpa.Delta_prime, mpa.H_prime)
+	mpa.Del_e = refraction_correction(spa, mpa.E0)
+	mpa.E = topocentric_elevation_angle_corrected(mpa.E0, mpa.Del_e)
+
+	mpa.Zenith = topocentric_zenith_angle(mpa.E)
+	mpa.Azimuth_astro = topocentric_azimuth_angle_astro(mpa.H_prime, spa.Latitude, mpa.Delta_prime)
+	mpa.Azimuth = topocentric_azimuth_angle(mpa.Azimuth_astro)
+
+	mpa.Elongation = moon_elongation(spa.alpha, spa.delta, mpa.Alpha, mpa.Delta)
+	mpa.Phase_angle = moon_phase_angle(spa.R*AU_KM, mpa.Elongation, mpa.Cap_delta)
+	mpa.Illuminated_fraction = moon_illuminated_fraction(mpa.Phase_angle)
+	mpa.Bright_limb = moon_bright_limb_angle(spa.alpha, spa.delta, mpa.Alpha, mpa.Delta)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the moon position and illumination for the inputs in structure
+//
+// The date, time and observer inputs of in are used as for Spa_compute (Slope, Azm_rotation
+// and Function are ignored). Illumination and bright limb use the geocentric sun and moon.
+// Algorithm and Precision are ignored too: the moon needs the nutation and the SPA sun of the
+// full chain.
+///////////////////////////////////////////////////////////////////////////////////////////
+func Mpa_compute(in Spa_data) (Mpa_result, error) {
+	var mpa Mpa_result
+
+	in.Function, in.Algorithm, in.Precision = SPA_ZA, nil, SPA_PRECISION_FULL
+	if err := Spa_validate(&in); err != nil {
+		return mpa, err
+	}
+	if err := apply_earth_orientation(&in); err != nil {
+		return mpa, err
+	}
+
+	calculate_julian_day(&in)
+	calculate_geocentric_sun_right_ascension_and_declination(&in)
+	calculate_mpa(&in, &mpa)
+
+	return mpa, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the moon position and illumination for the instant t
+///////////////////////////////////////////////////////////////////////////////////////////
+func Mpa_compute_time(t time.Time, in Spa_data) (Mpa_result, error) {
+	spa_set_time(&in, t)
+
+	return Mpa_compute(in)
+}
@@ -0,0 +1,331 @@
+package gosolar
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	MPA_ECLIPSE_NONE = iota //the moon does not cover any part of the sun for the observer
+	MPA_ECLIPSE_PARTIAL
+	MPA_ECLIPSE_ANNULAR //the moon is inside the sun disk at maximum
+	MPA_ECLIPSE_TOTAL   //the moon covers the whole sun disk at maximum
+)
+
+const (
+	MPA_CONTACT_C1  = iota //first contact, partial eclipse begins
+	MPA_CONTACT_C2         //second contact, total or annular eclipse begins
+	MPA_CONTACT_MAX        //maximum eclipse
+	MPA_CONTACT_C3         //third contact, total or annular eclipse ends
+	MPA_CONTACT_C4         //fourth contact, partial eclipse ends
+	MPA_CONTACT_COUNT
+)
+
+const (
+	SUN_SEMI_DIAMETER     = 959.63 / 3600.0   //sun semi-diameter at 1 AU [degrees]
+	MPA_ECLIPSE_LATITUDE  = 1.6               //largest moon latitude at new moon for which an eclipse is searched [degrees]
+	MPA_ECLIPSE_WINDOW    = 6 * time.Hour     //contacts are searched this long before and after new moon
+	MPA_ECLIPSE_STEP      = 10 * time.Minute  //sampling step used to bracket the maximum
+	MPA_ECLIPSE_TOLERANCE = time.Second       //time tolerance of the contacts and the maximum
+	MPA_LUNATION          = 29.530589 * 86400 //mean synodic month [seconds]
+)
+
+// Mpa_contact is the time and sun position of one eclipse contact.
+type Mpa_contact struct {
+	Time      time.Time // time of the contact (zero when it does not happen, e.g. C2 and C3 of a partial eclipse)
+	Elevation float64   // topocentric sun elevation angle (refraction corrected) at Time [degrees]
+	Azimuth   float64   // topocentric sun azimuth angle (eastward from north) at Time [degrees]
+}
+
+// Mpa_eclipse holds the local circumstances of a solar eclipse for the observer.
+type Mpa_eclipse struct {
+	Type        int                            // MPA_ECLIPSE_NONE, MPA_ECLIPSE_PARTIAL, MPA_ECLIPSE_ANNULAR or MPA_ECLIPSE_TOTAL
+	Visible     bool                           // sun center above the horizon (Horizon profile when set) at C1, maximum or C4
+	New_moon    time.Time                      // geocentric new moon the eclipse belongs to
+	Contacts    [MPA_CONTACT_COUNT]Mpa_contact // contacts indexed by MPA_CONTACT_C1 ... MPA_CONTACT_C4
+	Magnitude   float64                        // fraction of the sun diameter covered at maximum
+	Obscuration float64                        // fraction of the sun disk area covered at maximum [0 to 1]
+}
+
+// topocentric positions of sun and moon disks at one instant
+type eclipse_geometry struct {
+	spa        Spa_data // sun position (elevation, azimuth, obstruction)
+	separation float64  // angular distance between the disk centers [degrees]
+	r_sun      float64  // topocentric sun semi-diameter [degrees]
+	r_moon     float64  // topocentric moon semi-diameter [degrees]
+}
+
+// angular distance between two equatorial positions [degrees] (Vincenty's formula, exact near 0)
+func angular_separation(alpha1, delta1, alpha2, delta2 float64) float64 {
+	d1 := deg2rad(delta1)
+	d2 := deg2rad(delta2)
+	da := deg2rad(alpha2 - alpha1)
+
+	x := math.Cos(d1)*math.Sin(d2) - math.Sin(d1)*math.Cos(d2)*math.Cos(da)
+	y := math.Cos(d2) * math.Sin(da)
+
+	return rad2deg(math.Atan2(math.Sqrt(x*x+y*y), math.Sin(d1)*math.Sin(d2)+math.Cos(d1)*math.Cos(d2)*math.Cos(da)))
+}
+
+// geocentric moon semi-diameter from its horizontal parallax, enlarged as it rises toward the zenith
+func moon_topocentric_semi_diameter(pi, e0 float64) float64 {
+	s := rad2deg(math.Asin(MOON_SEMI_DIAMETER * math.Sin(deg2rad(pi))))
+
+	return s * (1.0 + math.Sin(deg2rad(e0))*math.Sin(deg2rad(pi)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Fraction of the sun disk (radius r_sun) covered by the moon disk (radius r_moon) at the
+// center distance d, from the area of the lens in which two circles overlap
+///////////////////////////////////////////////////////////////////////////////////////////////
+func eclipse_obscuration(r_sun, r_moon, d float64) float64 {
+	if d >= r_sun+r_moon {
+		return 0
+	}
+	if d <= math.Abs(r_sun-r_moon) {
+		if r_moon >= r_sun {
+			return 1
+		}
+		return (r_moon * r_moon) / (r_sun * r_sun)
+	}
+
+	a := math.Acos((d*d + r_sun*r_sun - r_moon*r_moon) / (2.0 * d * r_sun))
+	b := math.Acos((d*d + r_moon*r_moon - r_sun*r_sun) / (2.0 * d * r_moon))
+	lens := r_sun*r_sun*(a-math.Sin(2.0*a)/2.0) + r_moon*r_moon*(b-math.Sin(2.0*b)/2.0)
+
+	return lens / (math.Pi * r_sun * r_sun)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the topocentric sun and moon disks at the instant t from the inputs in
+///////////////////////////////////////////////////////////////////////////////////////////////
+func eclipse_geometry_at(t time.Time, in *Spa_data, g *eclipse_geometry) error {
+	var mpa Mpa_result
+
+	if err := spa_sun_position_at(t, in, &g.spa); err != nil {
+		return err
+	}
+	calculate_mpa(&g.spa, &mpa)
+
+	g.separation = angular_separation(g.spa.alpha_prime, g.spa.delta_prime, mpa.Alpha_prime, mpa.Delta_prime)
+	g.r_sun = SUN_SEMI_DIAMETER / g.spa.R
+	g.r_moon = moon_topocentric_semi_diameter(mpa.Pi, mpa.E0)
+
+	return nil
+}
+
+// distance of the disks from external (outer) or internal contact, negative while inside it [degrees]
+func eclipse_contact_offset(g *eclipse_geometry, outer bool) float64 {
+	if outer {
+		return g.separation - (g.r_sun + g.r_moon)
+	}
+
+	return g.separation - math.Abs(g.r_sun-g.r_moon)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Bisect the contact between a (outside of it) and b (inside of it) to MPA_ECLIPSE_TOLERANCE
+///////////////////////////////////////////////////////////////////////////////////////////////
+func eclipse_contact(a, b time.Time, in *Spa_data, outer bool, contact *Mpa_contact) error {
+	var g eclipse_geometry
+
+	for b.Sub(a) > MPA_ECLIPSE_TOLERANCE || a.Sub(b) > MPA_ECLIPSE_TOLERANCE {
+		m := a.Add(b.Sub(a) / 2)
+		if err := eclipse_geometry_at(m, in, &g); err != nil {
+			return err
+		}
+		if eclipse_contact_offset(&g, outer) > 0 {
+			a = m
+		} else {
+			b = m
+		}
+	}
+
+	t := a.Add(b.Sub(a) / 2)
+	if err := eclipse_geometry_at(t, in, &g); err != nil {
+		return err
+	}
+	*contact = Mpa_contact{Time: t, Elevation: g.spa.e, Azimuth: g.spa.Azimuth}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the local circumstances of the solar eclipse around the geocentric new moon
+///////////////////////////////////////////////////////////////////////////////////////////////
+func calculate_local_eclipse(new_moon time.Time, in *Spa_data, eclipse *Mpa_eclipse) error {
+	var g eclipse_geometry
+	var mpa Mpa_result
+
+	*eclipse = Mpa_eclipse{New_moon: new_moon}
+
+	// the moon passes too far north or south of the sun to eclipse it anywhere
+	if err := spa_sun_position_at(new_moon, in, &g.spa); err != nil {
+		return err
+	}
+	calculate_geocentric_moon_right_ascension_and_declination(&g.spa, &mpa)
+	if math.Abs(mpa.Beta) > MPA_ECLIPSE_LATITUDE {
+		return nil
+	}
+
+	// bracket the smallest separation by sampling, then narrow it by golden section search
+	start := new_moon.Add(-MPA_ECLIPSE_WINDOW)
+	end := new_moon.Add(MPA_ECLIPSE_WINDOW)
+
+	t_min, d_min := start, math.Inf(1)
+	for t := start; !t.After(end); t = t.Add(MPA_ECLIPSE_STEP) {
+		if err := eclipse_geometry_at(t, in, &g); err != nil {
+			return err
+		}
+		if g.separation < d_min {
+			t_min, d_min = t, g.separation
+		}
+	}
+
+	a, b := t_min.Add(-MPA_ECLIPSE_STEP), t_min.Add(MPA_ECLIPSE_STEP)
+	ratio := (math.Sqrt(5.0) - 1.0) / 2.0
+	for b.Sub(a) > MPA_ECLIPSE_TOLERANCE {
+		span := float64(b.Sub(a))
+		t1 := b.Add(-time.Duration(ratio * span))
+		t2 := a.Add(time.Duration(ratio * span))
+
+		if err := eclipse_geometry_at(t1, in, &g); err != nil {
+			return err
+		}
+		d1 := g.separation
+		if err := eclipse_geometry_at(t2, in, &g); err != nil {
+			return err
+		}
+		if d1 < g.separation {
+			b = t2
+		} else {
+			a = t1
+		}
+	}
+
+	t_max := a.Add(b.Sub(a) / 2)
+	if err := eclipse_geometry_at(t_max, in, &g); err != nil {
+		return err
+	}
+	if eclipse_contact_offset(&g, true) >= 0 {
+		return nil
+	}
+
+	eclipse.Type = MPA_ECLIPSE_PARTIAL
+	if eclipse_contact_offset(&g, false) < 0 {
+		eclipse.Type = MPA_ECLIPSE_ANNULAR
+		if g.r_moon > g.r_sun {
+			eclipse.Type = MPA_ECLIPSE_TOTAL
+		}
+	}
+	eclipse.Magnitude = (g.r_sun + g.r_moon - g.separation) / (2.0 * g.r_sun)
+	eclipse.Obscuration = eclipse_obscuration(g.r_sun, g.r_moon, g.separation)
+	eclipse.Contacts[MPA_CONTACT_MAX] = Mpa_contact{Time: t_max, Elevation: g.spa.e, Azimuth: g.spa.Azimuth}
+	visible := !g.spa.Obstructed
+
+	if err := eclipse_contact(start, t_max, in, true, &eclipse.Contacts[MPA_CONTACT_C1]); err != nil {
+		return err
+	}
+	if err := eclipse_contact(end, t_max, in, true, &eclipse.Contacts[MPA_CONTACT_C4]); err != nil {
+		return err
+	}
+	if eclipse.Type != MPA_ECLIPSE_PARTIAL {
+		if err := eclipse_contact(eclipse.Contacts[MPA_CONTACT_C1].Time, t_max, in, false,
+			&eclipse.Contacts[MPA_CONTACT_C2]); err != nil {
+			return err
+		}
+		if err := eclipse_contact(eclipse.Contacts[MPA_CONTACT_C4].Time, t_max, in, false,
+			&eclipse.Contacts[MPA_CONTACT_C3]); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range [2]int{MPA_CONTACT_C1, MPA_CONTACT_C4} {
+		if eclipse.Contacts[c].Elevation >= horizon_elevation(in, eclipse.Contacts[c].Azimuth) {
+			visible = true
+		}
+	}
+	eclipse.Visible = visible
+
+	return nil
+}
+
+// express the times of the eclipse in the location loc
+func (e *Mpa_eclipse) to_location(loc *time.Location) {
+	e.New_moon = e.New_moon.In(loc)
+	for i := range e.Contacts {
+		if !e.Contacts[i].Time.IsZero() {
+			e.Contacts[i].Time = e.Contacts[i].Time.In(loc)
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the local circumstances of the solar eclipse at the new moon nearest to t
+//
+// Type is MPA_ECLIPSE_NONE when the moon passes the sun without touching it for the observer
+// (Latitude, Longitude and Elevation of in), otherwise the contacts, magnitude and obscuration
+// are set; an eclipse with the sun below the horizon is returned with Visible false. The sun
+// and moon are topocentric, so the contacts are those seen from the site, and the moon
+// ephemeris limits them to a few seconds. Algorithm and Precision are ignored, both bodies
+// come from the full SPA chain. Times are returned in t's location.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Mpa_solar_eclipse(t time.Time, in Spa_data) (Mpa_eclipse, error) {
+	var eclipse Mpa_eclipse
+
+	half := time.Duration(MPA_LUNATION / 2 * float64(time.Second))
+	phases, err := Mpa_phases(t.Add(-half-time.Hour), t.Add(half+time.Hour), in)
+	if err != nil {
+		return eclipse, err
+	}
+
+	var new_moon time.Time
+	for _, p := range phases {
+		if p.Phase != MPA_PHASE_NEW {
+			continue
+		}
+		if new_moon.IsZero() || math.Abs(float64(p.Time.Sub(t))) < math.Abs(float64(new_moon.Sub(t))) {
+			new_moon = p.Time
+		}
+	}
+
+	in.Function, in.Algorithm, in.Precision = SPA_ZA, nil, SPA_PRECISION_FULL
+	if err := calculate_local_eclipse(new_moon, &in, &eclipse); err != nil {
+		return eclipse, err
+	}
+	eclipse.to_location(t.Location())
+
+	return eclipse, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// List the solar eclipses visible from the observer whose new moon is in [start, end)
+//
+// Only eclipses with Visible set are returned (see Mpa_solar_eclipse), in order and in
+// start's location.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Mpa_solar_eclipses(start, end time.Time, in Spa_data) ([]Mpa_eclipse, error) {
+	var eclipses []Mpa_eclipse
+	var eclipse Mpa_eclipse
+
+	phases, err := Mpa_phases(start, end, in)
+	if err != nil {
+		return nil, err
+	}
+
+	in.Function, in.Algorithm, in.Precision = SPA_ZA, nil, SPA_PRECISION_FULL
+	for _, p := range phases {
+		if p.Phase != MPA_PHASE_NEW {
+			continue
+		}
+		if err := calculate_local_eclipse(p.Time, &in, &eclipse); err != nil {
+			return nil, err
+		}
+		if eclipse.Visible {
+			eclipse.to_location(start.Location())
+			eclipses = append(eclipses, eclipse)
+		}
+	}
+
+	return eclipses, nil
+}
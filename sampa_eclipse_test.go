@@ -0,0 +1,98 @@
+package gosolar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMpa_solar_eclipse(t *testing.T) {
+	cdt := time.FixedZone("CDT", -5*3600)
+	edt := time.FixedZone("EDT", -4*3600)
+
+	carbondale := rts_site(37.7273, -89.2168)
+	carbondale.Elevation = 126
+	washington := rts_site(38.8895, -77.0353)
+	washington.Elevation = 20
+
+	// the eclipse of August 21, 2017 (NASA local circumstances, to the minute; the contacts
+	// are allowed a minute on either side of it): totality of 2m38s at Carbondale, Illinois,
+	// and 81% of the sun covered at Washington, DC
+	tests := []struct {
+		name        string
+		in          Spa_data
+		loc         *time.Location
+		kind        int
+		contacts    [MPA_CONTACT_COUNT]string
+		magnitude   float64
+		obscuration float64
+	}{
+		{"Carbondale", carbondale, cdt, MPA_ECLIPSE_TOTAL,
+			[MPA_CONTACT_COUNT]string{"11:52", "13:20", "13:21", "13:22", "14:47"}, 1.013, 1},
+		{"Washington", washington, edt, MPA_ECLIPSE_PARTIAL,
+			[MPA_CONTACT_COUNT]string{"13:17", "", "14:42", "", "16:01"}, 0.843, 0.811},
+	}
+
+	for _, tt := range tests {
+		e, err := Mpa_solar_eclipse(time.Date(2017, 8, 21, 12, 0, 0, 0, tt.loc), tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if e.Type != tt.kind || !e.Visible {
+			t.Errorf("%s: type %d, visible %v, want type %d", tt.name, e.Type, e.Visible, tt.kind)
+		}
+		if math.Abs(e.Magnitude-tt.magnitude) > 0.005 || math.Abs(e.Obscuration-tt.obscuration) > 0.005 {
+			t.Errorf("%s: magnitude %.3f, obscuration %.3f, want %.3f, %.3f", tt.name, e.Magnitude, e.Obscuration,
+				tt.magnitude, tt.obscuration)
+		}
+
+		for i, hm := range tt.contacts {
+			c := e.Contacts[i]
+			if hm == "" {
+				if !c.Time.IsZero() {
+					t.Errorf("%s: contact %d at %v, want none", tt.name, i, c.Time)
+				}
+				continue
+			}
+			want, err := time.ParseInLocation("2006-01-02 15:04", "2017-08-21 "+hm, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := c.Time.Sub(want); d < -time.Minute || d > 2*time.Minute {
+				t.Errorf("%s: contact %d at %v, want %s", tt.name, i, c.Time, hm)
+			}
+			if c.Elevation <= 0 {
+				t.Errorf("%s: sun elevation %v at contact %d", tt.name, c.Elevation, i)
+			}
+		}
+	}
+
+	// the lunar limb profile is not modeled, so totality may be off by a few seconds
+	e, err := Mpa_solar_eclipse(time.Date(2017, 8, 21, 12, 0, 0, 0, cdt), carbondale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totality := e.Contacts[MPA_CONTACT_C3].Time.Sub(e.Contacts[MPA_CONTACT_C2].Time)
+	if d := totality - (2*time.Minute + 38*time.Second); d.Abs() > 5*time.Second {
+		t.Errorf("Carbondale: totality lasts %v, want 2m38s", totality)
+	}
+
+	// the next new moon passes the sun far to the north
+	e, err = Mpa_solar_eclipse(time.Date(2017, 9, 20, 12, 0, 0, 0, cdt), carbondale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != MPA_ECLIPSE_NONE || e.New_moon.Day() != 20 {
+		t.Errorf("September 2017: type %d at the new moon %v", e.Type, e.New_moon)
+	}
+
+	// of the two solar eclipses of 2017, only the one of August is seen in Illinois
+	eclipses, err := Mpa_solar_eclipses(time.Date(2017, 1, 1, 0, 0, 0, 0, cdt), time.Date(2018, 1, 1, 0, 0, 0, 0, cdt),
+		carbondale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eclipses) != 1 || eclipses[0].New_moon.Month() != time.August {
+		t.Errorf("2017 eclipses at Carbondale: %v", eclipses)
+	}
+}
@@ -0,0 +1,366 @@
+package gosolar
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	BODY_RTS_ITERATIONS = 10             //most corrections applied to an approximate moon or planet transit time
+	BODY_RTS_CONVERGED  = 1e-7           //correction below which a transit time has converged [fraction of day]
+	BODY_RTS_SAME       = 1e-4           //transit times closer than this are the same transit [fraction of day]
+	MOON_SEMI_DIAMETER  = 0.2725         //moon semi-diameter in units of its horizontal parallax
+	MOON_PHASE_STEP     = 24 * time.Hour //sampling step used to bracket the principal phases
+)
+
+const (
+	MPA_PHASE_NEW = iota
+	MPA_PHASE_FIRST_QUARTER
+	MPA_PHASE_FULL
+	MPA_PHASE_LAST_QUARTER
+	MPA_PHASE_COUNT
+)
+
+// Mpa_rts holds the moon rise, transit and set of one local calendar day.
+type Mpa_rts struct {
+	State    int       // SPA_RTS_NORMAL, or SPA_RTS_POLAR_DAY/SPA_RTS_POLAR_NIGHT when the moon stays up/down
+	Moonrise time.Time // local moonrise, zero if the moon does not rise that day
+	Transit  time.Time // local moon (upper) transit, zero if there is none that day
+	Moonset  time.Time // local moonset, zero if the moon does not set that day
+}
+
+// Mpa_phase is one principal phase of the moon.
+type Mpa_phase struct {
+	Phase int       // MPA_PHASE_NEW, MPA_PHASE_FIRST_QUARTER, MPA_PHASE_FULL or MPA_PHASE_LAST_QUARTER
+	Time  time.Time // instant the apparent geocentric longitudes of moon and sun differ by Phase*90 degrees
+}
+
+////////////////////////////////////////////////////////////////////////
+// Geocentric body positions needed to interpolate rise, transit and set
+////////////////////////////////////////////////////////////////////////
+type body_rts_day struct {
+	nu    float64           // Greenwich sidereal time at 0h UT of the day [degrees]
+	alpha [JD_COUNT]float64 // right ascension at 0h TT of the day before, the day, the day after [degrees]
+	delta [JD_COUNT]float64 // declination at the same instants [degrees]
+	h0    [JD_COUNT]float64 // geocentric altitude of the body center at rise and set, same instants [degrees]
+}
+
+////////////////////////////////////////////////////////////////////////
+// Calculate the three-day geocentric moon ephemeris for the date in structure (0h UT)
+//
+// Rise and set are when the geocentric moon center is at 0.7275 pi - depression: the
+// parallax pi lowers it, the semi-diameter 0.2725 pi raises the upper limb, and depression
+// is the refraction and dip of the apparent horizon.
+////////////////////////////////////////////////////////////////////////
+func calculate_moon_rts_day(spa *Spa_data, depression float64, day *body_rts_day) {
+	var moon_rts Spa_data
+	var mpa Mpa_result
+	var i int
+
+	moon_rts = *spa
+
+	moon_rts.Hour, moon_rts.Minute, moon_rts.Second = 0, 0, 0
+	moon_rts.Delta_ut1, moon_rts.Timezone = 0.0, 0.0
+
+	moon_rts.Jd = julian_day_calendar(moon_rts.Year, moon_rts.Month, moon_rts.Day, moon_rts.Hour,
+		moon_rts.Minute, moon_rts.Second, moon_rts.Delta_ut1, moon_rts.Timezone, moon_rts.Calendar)
+
+	calculate_geocentric_sun_right_ascension_and_declination(&moon_rts)
+	day.nu = moon_rts.nu
+
+	moon_rts.Delta_t = 0
+	moon_rts.Jd--
+	for i = 0; i < JD_COUNT; i++ {
+		calculate_geocentric_sun_right_ascension_and_declination(&moon_rts)
+		calculate_geocentric_moon_right_ascension_and_declination(&moon_rts, &mpa)
+		day.alpha[i] = mpa.Alpha
+		day.delta[i] = mpa.Delta
+		day.h0[i] = (1.0-MOON_SEMI_DIAMETER)*mpa.Pi - depression
+		moon_rts.Jd++
+	}
+}
+
+// three point interpolation (Meeus 3.3), with the differences wrapped for angles that cycle
+func body_rts_interpolate(v *[JD_COUNT]float64, n float64, wrap bool) float64 {
+	a := v[JD_ZERO] - v[JD_MINUS]
+	b := v[JD_PLUS] - v[JD_ZERO]
+
+	if wrap {
+		a = limit_degrees180pm(a)
+		b = limit_degrees180pm(b)
+	}
+
+	return v[JD_ZERO] + n*(a+b+(b-a)*n)/2.0
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Refine an approximate transit time m [fraction of day from 0h UT] until it converges
+//
+// The moon moves about 13 degrees a day, so the single correction SPA applies to the sun is
+// repeated (Meeus, chapter 15).
+///////////////////////////////////////////////////////////////////////////////////////////////
+func body_rts_transit(spa *Spa_data, day *body_rts_day, m float64) (float64, bool) {
+	var i int
+
+	for i = 0; i < BODY_RTS_ITERATIONS; i++ {
+		nu := day.nu + 360.985647*m
+		n := m + spa.Delta_t/86400.0
+
+		alpha := body_rts_interpolate(&day.alpha, n, true)
+
+		dm := -limit_degrees180pm(nu+spa.Longitude-alpha) / 360.0
+
+		m += dm
+		if math.Abs(dm) < BODY_RTS_CONVERGED {
+			return m, true
+		}
+	}
+
+	return m, false
+}
+
+// geocentric altitude of the body less its rise/set altitude at m [fraction of day from 0h UT]
+func body_rts_altitude_offset(spa *Spa_data, day *body_rts_day, m float64) float64 {
+	nu := day.nu + 360.985647*m
+	n := m + spa.Delta_t/86400.0
+
+	alpha := body_rts_interpolate(&day.alpha, n, true)
+	delta := body_rts_interpolate(&day.delta, n, false)
+	h0 := body_rts_interpolate(&day.h0, n, false)
+
+	h_prime := limit_degrees180pm(nu + spa.Longitude - alpha)
+
+	return rts_sun_altitude(spa.Latitude, delta, h_prime) - h0
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Find the events of the UT day of the ephemeris and call found for each (m in [0, 1))
+//
+// The approximate transit is shifted by -1, 0 and +1 day before refining, because the moon's
+// transit of a UT day can start from the previous or next approximation (a day without one
+// comes about once a month). Rise and set are found as for the sun: the interpolated altitude
+// is sampled SUN_RTS_SAMPLES times a day and every crossing is bisected, since correcting the
+// hour angle diverges where the body only grazes its rise/set altitude.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func calculate_body_rts_events(spa *Spa_data, day *body_rts_day, found func(event int, m float64)) {
+	var transits []float64
+	var i, k int
+
+	m0 := limit_zero2one(approx_sun_transit_time(day.alpha[JD_ZERO], spa.Longitude, day.nu))
+
+transits:
+	for k = -1; k <= 1; k++ {
+		mk, ok := body_rts_transit(spa, day, m0+float64(k))
+		if !ok || mk < 0 || mk >= 1 {
+			continue
+		}
+		for _, seen := range transits {
+			if math.Abs(seen-mk) < BODY_RTS_SAME {
+				continue transits
+			}
+		}
+		transits = append(transits, mk)
+		found(SUN_TRANSIT, mk)
+	}
+
+	a, fa := 0.0, body_rts_altitude_offset(spa, day, 0)
+	for i = 1; i <= SUN_RTS_SAMPLES; i++ {
+		b := float64(i) / SUN_RTS_SAMPLES
+		fb := body_rts_altitude_offset(spa, day, b)
+
+		if math.Signbit(fa) != math.Signbit(fb) {
+			event := SUN_SET
+			if fb > fa {
+				event = SUN_RISE
+			}
+
+			lo, hi, flo := a, b, fa
+			for hi-lo > SUN_RTS_CONVERGED {
+				m := (lo + hi) / 2
+				fm := body_rts_altitude_offset(spa, day, m)
+				if math.Signbit(fm) == math.Signbit(flo) {
+					lo, flo = m, fm
+				} else {
+					hi = m
+				}
+			}
+
+			if m := (lo + hi) / 2; m < 1 {
+				found(event, m)
+			}
+		}
+
+		a, fa = b, fb
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate rise, transit and set of a body on the local calendar day of t
+//
+// in must have passed spa_rts_inputs; ephemeris fills the three-day ephemeris of the UT date
+// in its structure. The UT days before and after are solved too and events are kept when they
+// fall on the local day; the earliest of each kind is returned in t's location. Without rise
+// and set, the altitude at local noon tells whether the body stays up or down.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func body_local_day_events(t time.Time, in *Spa_data, ephemeris func(spa *Spa_data, day *body_rts_day),
+	events *[SUN_COUNT]time.Time) int {
+	var day, day0 body_rts_day
+	var body_rts0 Spa_data
+	var k int
+
+	loc := t.Location()
+	year, month, mday := t.Date()
+	day_start := time.Date(year, month, mday, 0, 0, 0, 0, loc)
+	day_end := day_start.AddDate(0, 0, 1)
+
+	for k = -1; k <= 1; k++ {
+		ut_day := time.Date(year, month, mday+k, 0, 0, 0, 0, time.UTC)
+
+		body_rts := *in
+		body_rts.Year, body_rts.Month, body_rts.Day = ut_day.Year(), int(ut_day.Month()), ut_day.Day()
+
+		ephemeris(&body_rts, &day)
+		calculate_body_rts_events(&body_rts, &day, func(event int, m float64) {
+			e := ut_day.Add(time.Duration(m * 86400e9)).In(loc)
+			if e.Before(day_start) || !e.Before(day_end) {
+				return
+			}
+			if events[event].IsZero() || e.Before(events[event]) {
+				events[event] = e
+			}
+		})
+		if k == 0 {
+			body_rts0, day0 = body_rts, day
+		}
+	}
+
+	if !events[SUN_RISE].IsZero() || !events[SUN_SET].IsZero() {
+		return SPA_RTS_NORMAL
+	}
+
+	ut_day0 := time.Date(year, month, mday, 0, 0, 0, 0, time.UTC)
+	noon := time.Date(year, month, mday, 12, 0, 0, 0, loc).Sub(ut_day0).Hours() / 24.0
+	if body_rts_altitude_offset(&body_rts0, &day0, noon) > 0 {
+		return SPA_RTS_POLAR_DAY
+	}
+
+	return SPA_RTS_POLAR_NIGHT
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate moonrise, moon transit and moonset of the local calendar day of t
+//
+// The observer inputs of in are used as for Spa_sun_rts (Function, Algorithm and Precision are
+// ignored); refraction and horizon dip come from Refraction, Atmos_refract and
+// Observer_height. Events are returned in t's location and a zero time means the event does
+// not happen on that local day.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Mpa_moon_rts(t time.Time, in Spa_data) (Mpa_rts, error) {
+	var events [SUN_COUNT]time.Time
+
+	in.Algorithm, in.Precision = nil, SPA_PRECISION_FULL
+	if err := spa_rts_inputs(t, &in); err != nil {
+		return Mpa_rts{}, err
+	}
+
+	dip := horizon_dip(&in)
+	depression := dip + horizon_refraction(&in, dip)
+
+	state := body_local_day_events(t, &in, func(spa *Spa_data, day *body_rts_day) {
+		calculate_moon_rts_day(spa, depression, day)
+	}, &events)
+
+	return Mpa_rts{State: state, Moonrise: events[SUN_RISE], Transit: events[SUN_TRANSIT],
+		Moonset: events[SUN_SET]}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Elongation of the moon from the sun in apparent geocentric longitude at t [0 to 360 degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func moon_phase_elongation(t time.Time, in *Spa_data) (float64, error) {
+	var mpa Mpa_result
+
+	spa := *in
+	spa_set_time(&spa, t.UTC())
+
+	if err := apply_earth_orientation(&spa); err != nil {
+		return 0, err
+	}
+
+	calculate_julian_day(&spa)
+	calculate_geocentric_sun_right_ascension_and_declination(&spa)
+	calculate_geocentric_moon_right_ascension_and_declination(&spa, &mpa)
+
+	return limit_degrees(mpa.Lamda - spa.lamda), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Find the new, first quarter, full and last quarter moons in [start, end)
+//
+// The elongation is sampled daily (it grows about 12 degrees a day) and each quarter it
+// passes is bisected to one second. Times are returned in start's location; Delta_t,
+// Delta_t_auto and Earth_orientation of in are used, its date, observer, Algorithm and
+// Precision are ignored (the elongation is from the apparent SPA sun longitude).
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Mpa_phases(start, end time.Time, in Spa_data) ([]Mpa_phase, error) {
+	var phases []Mpa_phase
+	var spa Spa_data
+
+	in.Function, in.Algorithm, in.Precision = SPA_ZA, nil, SPA_PRECISION_FULL
+	for _, t := range [2]time.Time{start, end} {
+		spa = in
+		spa_set_time(&spa, t.UTC())
+		if err := Spa_validate(&spa); err != nil {
+			return nil, err
+		}
+	}
+
+	loc := start.Location()
+
+	t0 := start
+	e0, err := moon_phase_elongation(t0, &in)
+	if err != nil {
+		return nil, err
+	}
+
+	for t0.Before(end) {
+		t1 := t0.Add(MOON_PHASE_STEP)
+		if t1.After(end) {
+			t1 = end
+		}
+		e1, err := moon_phase_elongation(t1, &in)
+		if err != nil {
+			return nil, err
+		}
+
+		q0, q1 := int(e0/90.0), int(e1/90.0)
+		if q0 != q1 {
+			phase := q1
+			target := 90.0 * float64(phase)
+
+			a, b := t0, t1
+			for b.Sub(a) > SPA_CROSSING_TOLERANCE {
+				m := a.Add(b.Sub(a) / 2)
+				e, err := moon_phase_elongation(m, &in)
+				if err != nil {
+					return nil, err
+				}
+				if limit_degrees180pm(e-target) < 0 {
+					a = m
+				} else {
+					b = m
+				}
+			}
+
+			t := a.Add(b.Sub(a) / 2)
+			if !t.Before(end) {
+				break
+			}
+			phases = append(phases, Mpa_phase{Phase: phase, Time: t.In(loc)})
+		}
+
+		t0, e0 = t1, e1
+	}
+
+	return phases, nil
+}
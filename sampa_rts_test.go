@@ -0,0 +1,170 @@
+package gosolar
+
+import (
+	"testing"
+	"time"
+)
+
+// geocentric altitude of the moon center less its rise/set altitude for the observer of in
+func moon_rts_offset(t *testing.T, e time.Time, in Spa_data) float64 {
+	t.Helper()
+
+	r, err := Mpa_compute_time(e, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rts_sun_altitude(in.Latitude, r.Delta, r.H) - ((1.0-MOON_SEMI_DIAMETER)*r.Pi - in.Atmos_refract)
+}
+
+func TestMpa_moon_rts(t *testing.T) {
+	// every moonrise and moonset of a month is within a minute of where the moon position of
+	// Mpa_compute reaches the rise/set altitude
+	for _, site := range []struct {
+		latitude, longitude float64
+		loc                 *time.Location
+	}{
+		{0, 0, time.UTC},
+		{39.742476, -105.1786, time.FixedZone("MST", -7*3600)},
+		{-33.87, 151.21, time.FixedZone("AEST", 10*3600)},
+		{52.52, 13.40, time.FixedZone("CET", 3600)},
+	} {
+		in := rts_site(site.latitude, site.longitude)
+		for d := 0; d < 30; d++ {
+			day := time.Date(2024, 3, 1+d, 12, 0, 0, 0, site.loc)
+
+			rts, err := Mpa_moon_rts(day, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rts.State != SPA_RTS_NORMAL {
+				t.Errorf("%v on %v: state %d", site.latitude, day, rts.State)
+			}
+
+			for _, ev := range []struct {
+				e      time.Time
+				rising bool
+			}{{rts.Moonrise, true}, {rts.Moonset, false}} {
+				if ev.e.IsZero() {
+					continue
+				}
+				if y, m, d := ev.e.Date(); y != day.Year() || m != day.Month() || d != day.Day() {
+					t.Errorf("%v: event %v is not on %v", site.latitude, ev.e, day)
+				}
+				before := moon_rts_offset(t, ev.e.Add(-time.Minute), in)
+				after := moon_rts_offset(t, ev.e.Add(time.Minute), in)
+				if (before < 0) != ev.rising || (after > 0) != ev.rising {
+					t.Errorf("%v: moon altitude offset %v before and %v after the event at %v", site.latitude,
+						before, after, ev.e)
+				}
+			}
+		}
+	}
+
+	// Longyearbyen: the moon stays down around the new moon of January 11, 2024, and up
+	// around the full moon of January 25, on which it does not transit
+	svalbard := rts_site(78.2232, 15.6267)
+	cet := time.FixedZone("CET", 3600)
+	for _, tt := range []struct {
+		day     int
+		state   int
+		transit bool
+	}{
+		{5, SPA_RTS_NORMAL, true},
+		{10, SPA_RTS_POLAR_NIGHT, true},
+		{15, SPA_RTS_NORMAL, true},
+		{22, SPA_RTS_POLAR_DAY, true},
+		{25, SPA_RTS_POLAR_DAY, false},
+		{29, SPA_RTS_NORMAL, true},
+	} {
+		day := time.Date(2024, 1, tt.day, 12, 0, 0, 0, cet)
+		rts, err := Mpa_moon_rts(day, svalbard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rts.State != tt.state || rts.Transit.IsZero() == tt.transit {
+			t.Errorf("January %d: state %d, transit %v; want state %d", tt.day, rts.State, rts.Transit, tt.state)
+		}
+		if tt.state == SPA_RTS_NORMAL {
+			continue
+		}
+		for h := 0; h < 24; h += 3 {
+			e := time.Date(2024, 1, tt.day, h, 0, 0, 0, cet)
+			if f := moon_rts_offset(t, e, svalbard); (f > 0) != (tt.state == SPA_RTS_POLAR_DAY) {
+				t.Errorf("January %d: moon altitude offset %v at %v in state %d", tt.day, f, e, tt.state)
+			}
+		}
+	}
+
+	// the moon comes from the full chain whatever the Precision
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	full, low := rts_site(52.52, 13.40), rts_site(52.52, 13.40)
+	low.Precision = SPA_PRECISION_LOW
+	want, err := Mpa_moon_rts(day, full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Mpa_moon_rts(day, low); err != nil || got != want {
+		t.Errorf("SPA_PRECISION_LOW: %+v, %v, want %+v", got, err, want)
+	}
+
+	if _, err := Mpa_moon_rts(time.Now(), rts_site(91, 0)); err == nil {
+		t.Error("latitude 91: no error")
+	}
+}
+
+func TestMpa_phases(t *testing.T) {
+	// Meeus examples 49.a and 49.b (times in TD, so Delta_t is 0): new moon 1977 February 18
+	// at 3h37m42s and last quarter 2044 January 21 at 23h48m17s
+	for _, tt := range []struct {
+		start time.Time
+		phase int
+		want  time.Time
+	}{
+		{time.Date(1977, 2, 14, 0, 0, 0, 0, time.UTC), MPA_PHASE_NEW, time.Date(1977, 2, 18, 3, 37, 42, 0, time.UTC)},
+		{time.Date(2044, 1, 17, 0, 0, 0, 0, time.UTC), MPA_PHASE_LAST_QUARTER, time.Date(2044, 1, 21, 23, 48, 17, 0, time.UTC)},
+	} {
+		phases, err := Mpa_phases(tt.start, tt.start.AddDate(0, 0, 7), Spa_data{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(phases) != 1 || phases[0].Phase != tt.phase {
+			t.Errorf("phases %v, want phase %d", phases, tt.phase)
+			continue
+		}
+		if d := phases[0].Time.Sub(tt.want); d.Abs() > 10*time.Second {
+			t.Errorf("phase %d at %v, Meeus gives %v", tt.phase, phases[0].Time, tt.want)
+		}
+	}
+
+	// a year has the quarters in turn, 6 to 9 days apart, returned in the location of start
+	cet := time.FixedZone("CET", 3600)
+	phases, err := Mpa_phases(time.Date(2024, 1, 1, 0, 0, 0, 0, cet), time.Date(2025, 1, 1, 0, 0, 0, 0, cet),
+		rts_site(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(phases) < 49 || len(phases) > 50 {
+		t.Errorf("%d phases in 2024", len(phases))
+	}
+	for i, p := range phases {
+		if p.Time.Location() != cet {
+			t.Errorf("phase at %v is not in CET", p.Time)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := phases[i-1]
+		if p.Phase != (prev.Phase+1)%MPA_PHASE_COUNT {
+			t.Errorf("phase %d at %v follows phase %d", p.Phase, p.Time, prev.Phase)
+		}
+		if d := p.Time.Sub(prev.Time).Hours() / 24; d < 6 || d > 9 {
+			t.Errorf("phase %d at %v comes %.1f days after the one before", p.Phase, p.Time, d)
+		}
+	}
+
+	if _, err := Mpa_phases(time.Date(7000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(7000, 2, 1, 0, 0, 0, 0, time.UTC),
+		Spa_data{}); err == nil {
+		t.Error("year 7000: no error")
+	}
+}
@@ -0,0 +1,69 @@
+package gosolar
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMpa_compute(t *testing.T) {
+	// Meeus examples 47.a and 48.a: the moon on 1992 April 12 at 0h TD
+	in := Spa_data{Year: 1992, Month: 4, Day: 12, Pressure: 1010, Temperature: 10, Atmos_refract: 0.5667}
+
+	r, err := Mpa_compute(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// beta, and with it alpha and delta, comes out 0.00043 degrees (1.6") from the printed
+	// -3.229126, well inside the 10" of the truncated series
+	for _, tt := range []struct {
+		name      string
+		got       float64
+		want      float64
+		tolerance float64
+	}{
+		{"L_prime", r.L_prime, 134.290182, 5e-7},
+		{"D", r.D, 113.842304, 5e-7},
+		{"M", r.M, 97.643514, 5e-7},
+		{"M_prime", r.M_prime, 5.150833, 5e-7},
+		{"F", r.F, 219.889721, 5e-7},
+		{"R", r.R, -16590875, 0.5},
+		{"Lamda_prime", r.Lamda_prime, 133.162655, 5e-7},
+		{"Beta", r.Beta, -3.229126, 5e-4},
+		{"Cap_delta", r.Cap_delta, 368409.7, 0.05},
+		{"Pi", r.Pi, 0.991990, 5e-7},
+		{"Lamda", r.Lamda, 133.167265, 1e-6},
+		{"Alpha", r.Alpha, 134.688470, 5e-4},
+		{"Delta", r.Delta, 13.768368, 5e-4},
+		{"Phase_angle", r.Phase_angle, 69.0756, 5e-4},
+		{"Illuminated_fraction", r.Illuminated_fraction, 0.6786, 5e-5},
+		{"Bright_limb", r.Bright_limb, 285.0, 0.05},
+	} {
+		if math.Abs(tt.got-tt.want) > tt.tolerance {
+			t.Errorf("%s = %.7f, Meeus gives %.7f", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// the topocentric moon of an observer is lowered by the parallax
+	in.Latitude, in.Longitude = 40, -100
+	r, err = Mpa_compute(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if geocentric := rts_sun_altitude(in.Latitude, r.Delta, r.H); r.E0 >= geocentric || geocentric-r.E0 > r.Pi {
+		t.Errorf("topocentric elevation %v, geocentric %v, parallax %v", r.E0, geocentric, r.Pi)
+	}
+
+	// the moon needs the nutation and the sun of the full chain, so Algorithm and Precision
+	// are ignored
+	grena := in
+	grena.Algorithm, grena.Precision = Spa_algorithm_grena3{}, SPA_PRECISION_LOW
+	if g, err := Mpa_compute(grena); err != nil || g != r {
+		t.Errorf("with Algorithm and Precision: %+v, %v, want %+v", g, err, r)
+	}
+
+	in.Month = 13
+	if _, err := Mpa_compute(in); err == nil {
+		t.Error("month 13: no error")
+	}
+}
@@ -1,7 +1,12 @@
 package gosolar
 
 import (
+	"fmt"
 	"math"
+
+	"github.com/Spectrafy/gosolar/astrotime"
+	"github.com/Spectrafy/gosolar/coordinates"
+	"github.com/Spectrafy/gosolar/internal/angle"
 )
 
 /////////////////////////////////////////////
@@ -137,20 +142,43 @@ type Spa_data struct {
 	Hour   int     // Observer local hour,   valid range: 0 to  24,  error code: 4
 	Minute int     // Observer local minute, valid range: 0 to  59,  error code: 5
 	Second float64 // Observer local second, valid range: 0 to <60,  error code: 6
+	// (0 to <61 in a leap second of Leap_seconds, e.g. 23:59:60 UTC)
+
+	Calendar int // Calendar of Year, Month and Day (from enumeration), in astronomical year
+	// numbering (year 0 is 1 BC, year -1 is 2 BC); SPA_CALENDAR_GREGORIAN_SWITCH, the default,
+	// is the Julian calendar until 1582 October 4 and the Gregorian one from 1582 October 15
+	// valid range: 0 to 2, error code: 22
+
+	Strict_date bool // Reject dates that do not exist in Calendar (e.g. February 31, or 1582
+	// October 10 with SPA_CALENDAR_GREGORIAN_SWITCH) instead of counting on from the month
+	// start (February 31 as March 3), error code: 3
 
 	Delta_ut1 float64 // Fractional second difference between UTC and UT which is used
 	// to adjust UTC for earth's irregular rotation rate and is derived
 	// from observation only and is reported in this bulletin:
 	// http://maia.usno.navy.mil/ser7/ser7.dat,
 	// where delta_ut1 = DUT1
+	// (or set Earth_orientation below to read it from IERS data, see package iers)
 	// valid range: -1 to 1 second (exclusive), error code 17
 
 	Delta_t float64 // Difference between earth rotation time and terrestrial time
 	// It is derived from observation only and is reported in this
 	// bulletin: http://maia.usno.navy.mil/ser7/ser7.dat,
 	// where delta_t = 32.184 + (TAI-UTC) - DUT1
+	// (or set Delta_t_auto or Earth_orientation below to fill it automatically)
 	// valid range: -8000 to 8000 seconds, error code: 7
 
+	Delta_t_auto bool // Estimate Delta_t from the built-in model (Spa_delta_t) instead of
+	// using the value above, which is then overwritten with the estimate
+
+	Earth_orientation Spa_earth_orientation // Source of DUT1 and TAI-UTC (e.g. *iers.Data).
+	// When set, Delta_ut1 and Delta_t = 32.184 + (TAI-UTC) - DUT1 are looked up
+	// for the UTC instant of the inputs and overwritten (Delta_t_auto is ignored)
+	// lookup outside the loaded data, error code: 18
+
+	Leap_seconds Spa_leap_seconds // Table of TAI-UTC (e.g. *iers.Leap_table) telling which minutes
+	// end with a leap second, so that Second may be 60; nil uses Earth_orientation, if set
+
 	Timezone float64 // Observer time zone (negative west of Greenwich)
 	// valid range: -18   to   18 hours,   error code: 8
 
@@ -163,6 +191,11 @@ type Spa_data struct {
 	Elevation float64 // Observer elevation [meters]
 	// valid range: -6500000 or higher meters,    error code: 11
 
+	Observer_height float64 // Observer eye height above the local horizon (tower, hilltop,
+	// aircraft above the surrounding terrain or sea) [meters], which dips the apparent
+	// horizon for sunrise and sunset; Elevation (above the ellipsoid) only sets parallax
+	// valid range:    0 or higher meters,        error code: 19
+
 	Pressure float64 // Annual average local pressure [millibars]
 	// valid range:    0 to 5000 millibars,       error code: 12
 
@@ -179,6 +212,24 @@ type Spa_data struct {
 	Atmos_refract float64 // Atmospheric refraction at sunrise and sunset (0.5667 deg is typical)
 	// valid range: -5   to   5 degrees, error code: 16
 
+	Refraction Spa_refraction // Atmospheric refraction model (e.g. Spa_refraction_nrel{}) used for
+	// the topocentric elevation and for the sun altitude at sunrise and sunset.
+	// nil is Spa_refraction_spa (the piecewise elevation correction with Atmos_refract at the horizon)
+
+	Horizon *Spa_horizon // Horizon profile (terrain and buildings) used for Obstructed and by
+	// Spa_sun_horizon_day; nil is the flat horizon
+
+	Precision int // Ephemeris precision level (SPA_PRECISION_FULL, the default, or a faster one
+	// truncating the earth periodic terms and the nutation series, see spa_precision.go); the
+	// moon and planet functions ignore it
+	// valid range: 0 to 3, error code: 20
+
+	Algorithm Spa_algorithm // Solar position algorithm (e.g. Spa_algorithm_grena3{}) used for the
+	// geocentric sun position instead of the SPA; Precision is then ignored and the SPA values
+	// it does not give are zero (see Spa_algorithm), nil is the SPA; the moon and planet
+	// functions ignore it
+	// Year outside the years of the algorithm, error code: 21
+
 	Function int // Switch to choose functions for desired output (from enumeration)
 
 	//-----------------Intermediate OUTPUT VALUES--------------------
@@ -238,6 +289,7 @@ type Spa_data struct {
 	azimuth_astro float64 //topocentric azimuth angle (westward from south) [for astronomers]
 	Azimuth       float64 //topocentric azimuth angle (eastward from north) [for navigators and solar radiation]
 	Incidence     float64 //surface incidence angle [degrees]
+	Obstructed    bool    //sun center (refraction corrected) is below the horizon profile
 
 	suntransit float64 //local sun transit time (or solar noon) [fractional hour]
 	Sunrise    float64 //local sunrise time (+/- 30 seconds) [fractional hour]
@@ -246,8 +298,10 @@ type Spa_data struct {
 }
 
 const (
-	PI         = 3.1415926535897932384626433832795028841971
-	SUN_RADIUS = 0.26667
+	PI          = 3.1415926535897932384626433832795028841971
+	SUN_RADIUS  = 0.26667
+	TT_TAI      = astrotime.TT_TAI // Terrestrial Time - International Atomic Time [seconds]
+	HORIZON_DIP = 0.0293           // dip of the sea horizon per square root of eye height [degrees/sqrt(meter)]
 
 	L_COUNT = 6
 	B_COUNT = 2
@@ -644,11 +698,11 @@ var PE_TERMS = [][]float64{{-171996, -174.2, 92025, 8.9},
 ///////////////////////////////////////////////
 
 func rad2deg(radians float64) float64 {
-	return (180.0 / PI) * radians
+	return angle.Rad2deg(radians)
 }
 
 func deg2rad(degrees float64) float64 {
-	return (PI / 180.0) * degrees
+	return angle.Deg2rad(degrees)
 }
 
 func integer(value float64) int {
@@ -656,15 +710,7 @@ func integer(value float64) int {
 }
 
 func limit_degrees(degrees float64) float64 {
-	var limited float64
-
-	degrees /= 360.0
-	limited = 360.0 * (degrees - math.Floor(degrees))
-	if limited < 0 {
-		limited += 360.0
-	}
-
-	return limited
+	return angle.Limit_degrees(degrees)
 }
 
 func limit_degrees180pm(degrees float64) float64 {
@@ -725,98 +771,87 @@ func third_order_polynomial(a, b, c, d, x float64) float64 {
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////
-func validate_inputs(spa *Spa_data) int {
-	if (spa.Year < -2000) || (spa.Year > 6000) {
-		return 1
-	}
-	if (spa.Month < 1) || (spa.Month > 12) {
-		return 2
-	}
-	if (spa.Day < 1) || (spa.Day > 31) {
-		return 3
-	}
-	if (spa.Hour < 0) || (spa.Hour > 24) {
-		return 4
-	}
-	if (spa.Minute < 0) || (spa.Minute > 59) {
-		return 5
-	}
-	if (spa.Second < 0) || (spa.Second >= 60) {
-		return 6
-	}
-	if (spa.Pressure < 0) || (spa.Pressure > 5000) {
-		return 12
-	}
-	if (spa.Temperature <= -273) || (spa.Temperature > 6000) {
-		return 13
-	}
-	if (spa.Delta_ut1 <= -1) || (spa.Delta_ut1 >= 1) {
-		return 17
-	}
-	if (spa.Hour == 24) && (spa.Minute > 0) {
-		return 5
-	}
-	if (spa.Hour == 24) && (spa.Second > 0) {
-		return 6
-	}
+// Check all inputs and append an error for every one out of range (in validate_inputs order)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func validate_inputs_all(spa *Spa_data) []*Spa_range_error {
+	var errs []*Spa_range_error
 
-	if math.Abs(spa.Delta_t) > 8000 {
-		return 7
-	}
-	if math.Abs(spa.Timezone) > 18 {
-		return 8
-	}
-	if math.Abs(spa.Longitude) > 180 {
-		return 9
-	}
-	if math.Abs(spa.Latitude) > 90 {
-		return 10
+	check := func(bad bool, field string, value, min, max float64, valid string, code int) {
+		if bad {
+			errs = append(errs, &Spa_range_error{Field: field, Value: value,
+				Min: min, Max: max, Range: valid, Code: code})
+		}
 	}
-	if math.Abs(spa.Atmos_refract) > 5 {
-		return 16
+
+	check((spa.Year < -2000) || (spa.Year > 6000), "Year", float64(spa.Year), -2000, 6000, "-2000 to 6000", 1)
+	check((spa.Month < 1) || (spa.Month > 12), "Month", float64(spa.Month), 1, 12, "1 to 12", 2)
+	check((spa.Day < 1) || (spa.Day > 31), "Day", float64(spa.Day), 1, 31, "1 to 31", 3)
+	if spa.Strict_date && (spa.Month >= 1) && (spa.Month <= 12) && (spa.Day >= 1) && (spa.Day <= 31) &&
+		!calendar_date_exists(spa.Year, spa.Month, spa.Day, spa.Calendar) {
+		last := days_in_month(spa.Year, spa.Month, spa.Calendar)
+		valid := fmt.Sprintf("1 to %d in %d-%02d", last, spa.Year, spa.Month)
+		if is_gregorian_switch_month(spa.Year, spa.Month, spa.Calendar) {
+			valid = "1 to 4 or 15 to 31 in 1582-10"
+		}
+		check(true, "Day", float64(spa.Day), 1, float64(last), valid, 3)
 	}
-	if spa.Elevation < -6500000 {
-		return 11
+	check((spa.Hour < 0) || (spa.Hour > 24), "Hour", float64(spa.Hour), 0, 24, "0 to 24", 4)
+	check((spa.Minute < 0) || (spa.Minute > 59), "Minute", float64(spa.Minute), 0, 59, "0 to 59", 5)
+	check((spa.Second < 0) || ((spa.Second >= 60) && !leap_second(spa)), "Second", spa.Second, 0, 60,
+		"0 to <60 (<61 in a leap second)", 6)
+	check((spa.Pressure < 0) || (spa.Pressure > 5000), "Pressure", spa.Pressure, 0, 5000, "0 to 5000 millibars", 12)
+	check((spa.Temperature <= -273) || (spa.Temperature > 6000), "Temperature", spa.Temperature, -273, 6000,
+		"-273 (exclusive) to 6000 degrees Celsius", 13)
+	check((spa.Delta_ut1 <= -1) || (spa.Delta_ut1 >= 1), "Delta_ut1", spa.Delta_ut1, -1, 1, "-1 to 1 second (exclusive)", 17)
+	check((spa.Hour == 24) && (spa.Minute > 0), "Minute", float64(spa.Minute), 0, 0, "0 when Hour is 24", 5)
+	check((spa.Hour == 24) && (spa.Second > 0), "Second", spa.Second, 0, 0, "0 when Hour is 24", 6)
+
+	check(!spa.Delta_t_auto && math.Abs(spa.Delta_t) > 8000, "Delta_t", spa.Delta_t, -8000, 8000, "-8000 to 8000 seconds", 7)
+	check(math.Abs(spa.Timezone) > 18, "Timezone", spa.Timezone, -18, 18, "-18 to 18 hours", 8)
+	check(math.Abs(spa.Longitude) > 180, "Longitude", spa.Longitude, -180, 180, "-180 to 180 degrees", 9)
+	check(math.Abs(spa.Latitude) > 90, "Latitude", spa.Latitude, -90, 90, "-90 to 90 degrees", 10)
+	check(math.Abs(spa.Atmos_refract) > 5, "Atmos_refract", spa.Atmos_refract, -5, 5, "-5 to 5 degrees", 16)
+	check(spa.Elevation < -6500000, "Elevation", spa.Elevation, -6500000, math.Inf(1), "-6500000 or higher meters", 11)
+	check(spa.Observer_height < 0, "Observer_height", spa.Observer_height, 0, math.Inf(1), "0 or higher meters", 19)
+	check((spa.Calendar < 0) || (spa.Calendar >= SPA_CALENDAR_COUNT), "Calendar", float64(spa.Calendar),
+		0, SPA_CALENDAR_COUNT-1, "0 to 2", 22)
+	check((spa.Precision < 0) || (spa.Precision >= SPA_PRECISION_COUNT), "Precision", float64(spa.Precision),
+		0, SPA_PRECISION_COUNT-1, "0 to 3", 20)
+
+	if spa.Algorithm != nil {
+		if first, last := spa.Algorithm.Years(); (spa.Year < first) || (spa.Year > last) {
+			check(true, "Year", float64(spa.Year), float64(first), float64(last),
+				fmt.Sprintf("%d to %d for the algorithm", first, last), 21)
+		}
 	}
 
 	if (spa.Function == SPA_ZA_INC) || (spa.Function == SPA_ALL) {
-		if math.Abs(spa.Slope) > 360 {
-			return 14
-		}
-		if math.Abs(spa.Azm_rotation) > 360 {
-			return 15
-		}
+		check(math.Abs(spa.Slope) > 360, "Slope", spa.Slope, -360, 360, "-360 to 360 degrees", 14)
+		check(math.Abs(spa.Azm_rotation) > 360, "Azm_rotation", spa.Azm_rotation, -360, 360, "-360 to 360 degrees", 15)
 	}
 
-	return 0
+	return errs
 }
 
-///////////////////////////////////////////////////////////////////////////////////////////////
-func julian_day(year, month, day, hour, minute int, second, dut1, tz float64) float64 {
-	var day_decimal, julian_day, a float64
-
-	day_decimal = float64(day) + (float64(hour)-tz+(float64(minute)+(second+dut1)/60.0)/60.0)/24.0
-
-	if month < 3 {
-		month += 12
-		year--
+func validate_inputs(spa *Spa_data) int {
+	if errs := validate_inputs_all(spa); len(errs) > 0 {
+		return errs[0].Code
 	}
 
-	// julian_day = int(365.25*(float64(year)+4716.0)) + int(30.6001*(float64(month)+1)) + day_decimal - 1524.5
-	julian_day = float64(int(365.25*(float64(year)+4716.0))) + float64(int((30.6001 * (float64(month) + 1)))) + day_decimal - 1524.5
+	return 0
+}
 
-	if julian_day > 2299160.0 {
-		// a = int(year / 100)
-		a = float64(int(year / 100))
-		// julian_day += (2 - a + int(a/4))
-		julian_day += (2 - a + float64(int(a/4)))
-	}
+///////////////////////////////////////////////////////////////////////////////////////////////
+func julian_day(year, month, day, hour, minute int, second, dut1, tz float64) float64 {
+	return julian_day_calendar(year, month, day, hour, minute, second, dut1, tz, SPA_CALENDAR_GREGORIAN_SWITCH)
+}
 
-	return julian_day
+func julian_day_calendar(year, month, day, hour, minute int, second, dut1, tz float64, calendar int) float64 {
+	return astrotime.Julian_day(year, month, day, hour, minute, second+dut1, tz, calendar)
 }
 
 func julian_century(jd float64) float64 {
-	return (jd - 2451545.0) / 36525.0
+	return astrotime.J2000_centuries(jd)
 }
 
 func julian_ephemeris_day(jd, delta_t float64) float64 {
@@ -824,7 +859,7 @@ func julian_ephemeris_day(jd, delta_t float64) float64 {
 }
 
 func julian_ephemeris_century(jde float64) float64 {
-	return (jde - 2451545.0) / 36525.0
+	return astrotime.J2000_centuries(jde)
 }
 
 func julian_ephemeris_millennium(jce float64) float64 {
@@ -855,39 +890,50 @@ func earth_values(term_sum []float64, count int, jme float64) float64 {
 	return sum
 }
 
-func earth_heliocentric_longitude(jme float64) float64 {
-	sum := make([]float64, L_COUNT)
+// powers of jme with terms to sum: the higher powers a precision level drops add nothing
+func earth_powers(subcount []int) int {
+	count := len(subcount)
+
+	for count > 0 && subcount[count-1] == 0 {
+		count--
+	}
+
+	return count
+}
+
+func earth_heliocentric_longitude(jme float64, subcount *[L_COUNT]int) float64 {
+	var sum [L_COUNT]float64
 	var i int
 
 	for i = 0; i < L_COUNT; i++ {
-		sum[i] = earth_periodic_term_summation(L_TERMS[i], l_subcount[i], jme)
+		sum[i] = earth_periodic_term_summation(L_TERMS[i], subcount[i], jme)
 	}
 
-	return limit_degrees(rad2deg(earth_values(sum, L_COUNT, jme)))
+	return limit_degrees(rad2deg(earth_values(sum[:], earth_powers(subcount[:]), jme)))
 
 }
 
-func earth_heliocentric_latitude(jme float64) float64 {
-	sum := make([]float64, B_COUNT)
+func earth_heliocentric_latitude(jme float64, subcount *[B_COUNT]int) float64 {
+	var sum [B_COUNT]float64
 	var i int
 
 	for i = 0; i < B_COUNT; i++ {
-		sum[i] = earth_periodic_term_summation(B_TERMS[i], b_subcount[i], jme)
+		sum[i] = earth_periodic_term_summation(B_TERMS[i], subcount[i], jme)
 	}
 
-	return rad2deg(earth_values(sum, B_COUNT, jme))
+	return rad2deg(earth_values(sum[:], earth_powers(subcount[:]), jme))
 
 }
 
-func earth_radius_vector(jme float64) float64 {
-	sum := make([]float64, R_COUNT)
+func earth_radius_vector(jme float64, subcount *[R_COUNT]int) float64 {
+	var sum [R_COUNT]float64
 	var i int
 
 	for i = 0; i < R_COUNT; i++ {
-		sum[i] = earth_periodic_term_summation(R_TERMS[i], r_subcount[i], jme)
+		sum[i] = earth_periodic_term_summation(R_TERMS[i], subcount[i], jme)
 	}
 
-	return earth_values(sum, R_COUNT, jme)
+	return earth_values(sum[:], earth_powers(subcount[:]), jme)
 
 }
 
@@ -936,13 +982,13 @@ func xy_term_summation(i int, x []float64) float64 { // x[TERM_X_COUNT]
 	return sum
 }
 
-func nutation_longitude_and_obliquity(jce float64, x []float64, del_psi, del_epsilon *float64) { // x[TERM_X_COUNT]
+func nutation_longitude_and_obliquity(jce float64, x []float64, y_count int, del_psi, del_epsilon *float64) { // x[TERM_X_COUNT]
 	var i int
 	var xy_term_sum float64
 	sum_psi := 0.0
 	sum_epsilon := 0.0
 
-	for i = 0; i < Y_COUNT; i++ {
+	for i = 0; i < y_count; i++ {
 		xy_term_sum = deg2rad(xy_term_summation(i, x))
 		sum_psi += (float64(PE_TERMS[i][TERM_PSI_A]) + jce*float64(PE_TERMS[i][TERM_PSI_B])) * math.Sin(xy_term_sum)
 		sum_epsilon += (float64(PE_TERMS[i][TERM_EPS_C]) + jce*float64(PE_TERMS[i][TERM_EPS_D])) * math.Cos(xy_term_sum)
@@ -971,29 +1017,22 @@ func apparent_sun_longitude(theta, delta_psi, delta_tau float64) float64 {
 	return theta + delta_psi + delta_tau
 }
 
-func greenwich_mean_sidereal_time(jd, jc float64) float64 {
-	return limit_degrees(280.46061837 + 360.98564736629*(jd-2451545.0) +
-		jc*jc*(0.000387933-jc/38710000.0))
+func greenwich_mean_sidereal_time(jd float64) float64 {
+	return astrotime.Gmst(jd)
 }
 
 func greenwich_sidereal_time(nu0, delta_psi, epsilon float64) float64 {
-	return nu0 + delta_psi*math.Cos(deg2rad(epsilon))
+	return nu0 + astrotime.Equation_of_equinoxes(delta_psi, epsilon)
 }
 
 func geocentric_right_ascension(lamda, epsilon, beta float64) float64 {
-	lamda_rad := deg2rad(lamda)
-	epsilon_rad := deg2rad(epsilon)
-
-	return limit_degrees(rad2deg(math.Atan2(math.Sin(lamda_rad)*math.Cos(epsilon_rad)-
-		math.Tan(deg2rad(beta))*math.Sin(epsilon_rad), math.Cos(lamda_rad))))
+	return coordinates.Ecliptic_to_equatorial(coordinates.Ecliptic{Longitude: lamda, Latitude: beta},
+		epsilon).Right_ascension
 }
 
 func geocentric_declination(beta, epsilon, lamda float64) float64 {
-	beta_rad := deg2rad(beta)
-	epsilon_rad := deg2rad(epsilon)
-
-	return rad2deg(math.Asin(math.Sin(beta_rad)*math.Cos(epsilon_rad) +
-		math.Cos(beta_rad)*math.Sin(epsilon_rad)*math.Sin(deg2rad(lamda))))
+	return coordinates.Ecliptic_to_equatorial(coordinates.Ecliptic{Longitude: lamda, Latitude: beta},
+		epsilon).Declination
 }
 
 func observer_hour_angle(nu, longitude, alpha_deg float64) float64 {
@@ -1005,22 +1044,8 @@ func sun_equatorial_horizontal_parallax(r float64) float64 {
 }
 
 func right_ascension_parallax_and_topocentric_dec(latitude, elevation, xi, h, delta float64, delta_alpha, delta_prime *float64) {
-	var delta_alpha_rad float64
-	lat_rad := deg2rad(latitude)
-	xi_rad := deg2rad(xi)
-	h_rad := deg2rad(h)
-	delta_rad := deg2rad(delta)
-	u := math.Atan(0.99664719 * math.Tan(lat_rad))
-	y := 0.99664719*math.Sin(u) + elevation*math.Sin(lat_rad)/6378140.0
-	x := math.Cos(u) + elevation*math.Cos(lat_rad)/6378140.0
-
-	delta_alpha_rad = math.Atan2(-x*math.Sin(xi_rad)*math.Sin(h_rad),
-		math.Cos(delta_rad)-x*math.Sin(xi_rad)*math.Cos(h_rad))
-
-	*delta_prime = rad2deg(math.Atan2((math.Sin(delta_rad)-y*math.Sin(xi_rad))*math.Cos(delta_alpha_rad),
-		math.Cos(delta_rad)-x*math.Sin(xi_rad)*math.Cos(h_rad)))
-
-	*delta_alpha = rad2deg(delta_alpha_rad)
+	*delta_alpha, *delta_prime = coordinates.Parallax_in_right_ascension_and_declination(
+		coordinates.Hour_angle{Hour_angle: h, Declination: delta}, xi, latitude, elevation)
 }
 
 func topocentric_right_ascension(alpha_deg, delta_alpha float64) float64 {
@@ -1033,11 +1058,8 @@ func topocentric_local_hour_angle(h, delta_alpha float64) float64 {
 }
 
 func topocentric_elevation_angle(latitude, delta_prime, h_prime float64) float64 {
-	lat_rad := deg2rad(latitude)
-	delta_prime_rad := deg2rad(delta_prime)
-
-	return rad2deg(math.Asin(math.Sin(lat_rad)*math.Sin(delta_prime_rad) +
-		math.Cos(lat_rad)*math.Cos(delta_prime_rad)*math.Cos(deg2rad(h_prime))))
+	return coordinates.Hour_angle_to_horizontal(coordinates.Hour_angle{Hour_angle: h_prime, Declination: delta_prime},
+		latitude).Elevation
 }
 
 func atmospheric_refraction_correction(pressure, temperature, atmos_refract, e0 float64) float64 {
@@ -1052,14 +1074,7 @@ func atmospheric_refraction_correction(pressure, temperature, atmos_refract, e0
 		del_e = 0.00452*PT/math.Tan(deg2rad(e0))
 	}
 
-	// if e0 >= -1*(SUN_RADIUS+atmos_refract) {
-	// 	a := pressure * 283.0 * 1.02
-	// 	b := 1010.0 * (temperature + 273.15) * 60.0 * math.Tan(deg2rad(e0+10.3/(e0+5.11)))
-	// 	del_e = a / b
-	// 	//del_e = (pressure / 1010.0) * (283.0 / (273.15 + temperature)) *
-	// 	//	1.02 / (60.0 * math.Tan(deg2rad(e0+10.3/(e0+5.11))))
-	// }
-	//fmt.Println(del_e)
+
 	return del_e
 }
 
@@ -1107,12 +1122,17 @@ func approx_sun_transit_time(alpha_zero, longitude, nu float64) float64 {
 	return (alpha_zero - longitude - nu) / 360.0
 }
 
-func sun_hour_angle_at_rise_set(latitude, delta_zero, h0_prime float64) float64 {
-	h0 := -99999.0
+func sun_hour_angle_at_rise_set_argument(latitude, delta_zero, h0_prime float64) float64 {
 	latitude_rad := deg2rad(latitude)
 	delta_zero_rad := deg2rad(delta_zero)
-	argument := (math.Sin(deg2rad(h0_prime)) - math.Sin(latitude_rad)*math.Sin(delta_zero_rad)) /
+
+	return (math.Sin(deg2rad(h0_prime)) - math.Sin(latitude_rad)*math.Sin(delta_zero_rad)) /
 		(math.Cos(latitude_rad) * math.Cos(delta_zero_rad))
+}
+
+func sun_hour_angle_at_rise_set(latitude, delta_zero, h0_prime float64) float64 {
+	h0 := -99999.0
+	argument := sun_hour_angle_at_rise_set_argument(latitude, delta_zero, h0_prime)
 
 	if math.Abs(argument) <= 1 {
 		h0 = limit_degrees180(rad2deg(math.Acos(argument)))
@@ -1156,12 +1176,24 @@ func sun_rise_and_set(m_rts, h_rts, delta_prime []float64, latitude float64, h_p
 		(360.0*math.Cos(deg2rad(delta_prime[sun]))*math.Cos(deg2rad(latitude))*math.Sin(deg2rad(h_prime[sun])))
 }
 
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the Julian day (and Delta T, when it is to be estimated) and put into structure
+////////////////////////////////////////////////////////////////////////////////////////////////
+func calculate_julian_day(spa *Spa_data) {
+	if spa.Delta_t_auto && spa.Earth_orientation == nil {
+		spa.Delta_t = Spa_delta_t(delta_t_decimal_year(spa.Year, spa.Month))
+	}
+
+	spa.Jd = julian_day_calendar(spa.Year, spa.Month, spa.Day, spa.Hour,
+		spa.Minute, spa.Second, spa.Delta_ut1, spa.Timezone, spa.Calendar)
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////
 // Calculate required SPA parameters to get the right ascension (alpha) and declination (delta)
 // Note: JD must be already calculated and in structure
 ////////////////////////////////////////////////////////////////////////////////////////////////
 func calculate_geocentric_sun_right_ascension_and_declination(spa *Spa_data) {
-	var x = make([]float64, TERM_X_COUNT)
+	var x [TERM_X_COUNT]float64
 
 	spa.jc = julian_century(spa.Jd)
 
@@ -1169,9 +1201,16 @@ func calculate_geocentric_sun_right_ascension_and_declination(spa *Spa_data) {
 	spa.jce = julian_ephemeris_century(spa.jde)
 	spa.jme = julian_ephemeris_millennium(spa.jce)
 
-	spa.L = earth_heliocentric_longitude(spa.jme)
-	spa.B = earth_heliocentric_latitude(spa.jme)
-	spa.R = earth_radius_vector(spa.jme)
+	if spa.Algorithm != nil {
+		calculate_algorithm_sun(spa)
+		return
+	}
+
+	terms := &precision_terms[spa.Precision]
+
+	spa.L = earth_heliocentric_longitude(spa.jme, &terms.l)
+	spa.B = earth_heliocentric_latitude(spa.jme, &terms.b)
+	spa.R = earth_radius_vector(spa.jme, &terms.r)
 
 	spa.theta = geocentric_longitude(spa.L)
 	spa.beta = geocentric_latitude(spa.B)
@@ -1182,87 +1221,159 @@ func calculate_geocentric_sun_right_ascension_and_declination(spa *Spa_data) {
 	x[TERM_X3], spa.x3 = argument_latitude_moon(spa.jce), argument_latitude_moon(spa.jce)
 	x[TERM_X4], spa.x4 = ascending_longitude_moon(spa.jce), ascending_longitude_moon(spa.jce)
 
-	nutation_longitude_and_obliquity(spa.jce, x, &(spa.Del_psi), &(spa.Del_epsilon))
+	nutation_longitude_and_obliquity(spa.jce, x[:], terms.y, &(spa.Del_psi), &(spa.Del_epsilon))
 
 	spa.epsilon0 = ecliptic_mean_obliquity(spa.jme)
 	spa.Epsilon = ecliptic_true_obliquity(spa.Del_epsilon, spa.epsilon0)
 
 	spa.del_tau = aberration_correction(spa.R)
 	spa.lamda = apparent_sun_longitude(spa.theta, spa.Del_psi, spa.del_tau)
-	spa.nu0 = greenwich_mean_sidereal_time(spa.Jd, spa.jc)
+	spa.nu0 = greenwich_mean_sidereal_time(spa.Jd)
 	spa.nu = greenwich_sidereal_time(spa.nu0, spa.Del_psi, spa.Epsilon)
 
 	spa.alpha = geocentric_right_ascension(spa.lamda, spa.Epsilon, spa.beta)
 	spa.delta = geocentric_declination(spa.beta, spa.Epsilon, spa.lamda)
 }
 
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Nutation in longitude and obliquity and the true obliquity of the ecliptic [degrees] at the
+// Julian ephemeris day jde (TT), with all terms of the SPA nutation series
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_nutation(jde float64) (del_psi, del_epsilon, epsilon float64) {
+	var x [TERM_X_COUNT]float64
+
+	jce := julian_ephemeris_century(jde)
+
+	x[TERM_X0] = mean_elongation_moon_sun(jce)
+	x[TERM_X1] = mean_anomaly_sun(jce)
+	x[TERM_X2] = mean_anomaly_moon(jce)
+	x[TERM_X3] = argument_latitude_moon(jce)
+	x[TERM_X4] = ascending_longitude_moon(jce)
+
+	nutation_longitude_and_obliquity(jce, x[:], Y_COUNT, &del_psi, &del_epsilon)
+	epsilon = ecliptic_true_obliquity(del_epsilon, ecliptic_mean_obliquity(julian_ephemeris_millennium(jce)))
+
+	return del_psi, del_epsilon, epsilon
+}
+
 ////////////////////////////////////////////////////////////////////////
-// Calculate Equation of Time (EOT) and Sun Rise, Transit, & Set (RTS)
+// Geocentric sun positions needed to interpolate rise, transit and set
+////////////////////////////////////////////////////////////////////////
+type sun_rts_day struct {
+	nu    float64           // Greenwich sidereal time at 0h UT of the day [degrees]
+	alpha [JD_COUNT]float64 // right ascension at 0h TT of the day before, the day, the day after [degrees]
+	delta [JD_COUNT]float64 // declination at 0h TT of the day before, the day, the day after [degrees]
+}
+
+////////////////////////////////////////////////////////////////////////
+// Sun transit and rise/set (for a given sun altitude) of one day
 ////////////////////////////////////////////////////////////////////////
+type sun_rts_events struct {
+	h0      float64            // local hour angle at rise/set [degrees], -99999 if there is none
+	m       [SUN_COUNT]float64 // transit, rise, set [fraction of day from 0h UT], rise/set only if h0 >= 0
+	h_prime [SUN_COUNT]float64 // local hour angle at the approximate event times [degrees]
+	h       [SUN_COUNT]float64 // sun altitude at the approximate event times [degrees]
+}
 
-func calculate_eot_and_sun_rise_transit_set(spa *Spa_data) {
+////////////////////////////////////////////////////////////////////////
+// Calculate the three-day geocentric ephemeris for the date in structure (0h UT)
+////////////////////////////////////////////////////////////////////////
+func calculate_sun_rts_day(spa *Spa_data, day *sun_rts_day) {
 	var sun_rts Spa_data
-	var nu, m, h0, n float64
-	var alpha, delta = make([]float64, JD_COUNT), make([]float64, JD_COUNT)
-	var m_rts, nu_rts, h_rts = make([]float64, SUN_COUNT), make([]float64, SUN_COUNT), make([]float64, SUN_COUNT)
-	var alpha_prime, delta_prime, h_prime = make([]float64, SUN_COUNT), make([]float64, SUN_COUNT), make([]float64, SUN_COUNT)
-	h0_prime := -1 * (SUN_RADIUS + spa.Atmos_refract)
 	var i int
 
 	sun_rts = *spa
-	m = sun_mean_longitude(spa.jme)
-	spa.eot = eot(m, spa.alpha, spa.Del_psi, spa.Epsilon)
 
 	sun_rts.Hour, sun_rts.Minute, sun_rts.Second = 0, 0, 0
 	sun_rts.Delta_ut1, sun_rts.Timezone = 0.0, 0.0
 
-	sun_rts.Jd = julian_day(sun_rts.Year, sun_rts.Month, sun_rts.Day, sun_rts.Hour,
-		sun_rts.Minute, sun_rts.Second, sun_rts.Delta_ut1, sun_rts.Timezone)
+	sun_rts.Jd = julian_day_calendar(sun_rts.Year, sun_rts.Month, sun_rts.Day, sun_rts.Hour,
+		sun_rts.Minute, sun_rts.Second, sun_rts.Delta_ut1, sun_rts.Timezone, sun_rts.Calendar)
 
 	calculate_geocentric_sun_right_ascension_and_declination(&sun_rts)
-	nu = sun_rts.nu
+	day.nu = sun_rts.nu
 
 	sun_rts.Delta_t = 0
 	sun_rts.Jd--
 	for i = 0; i < JD_COUNT; i++ {
 		calculate_geocentric_sun_right_ascension_and_declination(&sun_rts)
-		alpha[i] = sun_rts.alpha
-		delta[i] = sun_rts.delta
+		day.alpha[i] = sun_rts.alpha
+		day.delta[i] = sun_rts.delta
 		sun_rts.Jd++
 	}
+}
 
-	m_rts[SUN_TRANSIT] = approx_sun_transit_time(alpha[JD_ZERO], spa.Longitude, nu)
-	h0 = sun_hour_angle_at_rise_set(spa.Latitude, delta[JD_ZERO], h0_prime)
+////////////////////////////////////////////////////////////////////////
+// Calculate transit and the times the sun center reaches altitude h0_prime
+////////////////////////////////////////////////////////////////////////
+func calculate_sun_rts_events(spa *Spa_data, day *sun_rts_day, h0_prime float64, ev *sun_rts_events) {
+	var m_rts, nu_rts [SUN_COUNT]float64
+	var alpha_prime, delta_prime [SUN_COUNT]float64
+	var n float64
+	var i, count int
+
+	m_rts[SUN_TRANSIT] = approx_sun_transit_time(day.alpha[JD_ZERO], spa.Longitude, day.nu)
+	ev.h0 = sun_hour_angle_at_rise_set(spa.Latitude, day.delta[JD_ZERO], h0_prime)
+
+	if ev.h0 >= 0 {
+		approx_sun_rise_and_set(m_rts[:], ev.h0)
+		count = SUN_COUNT
+	} else {
+		m_rts[SUN_TRANSIT] = limit_zero2one(m_rts[SUN_TRANSIT])
+		count = SUN_TRANSIT + 1
+	}
 
-	if h0 >= 0 {
+	for i = 0; i < count; i++ {
 
-		approx_sun_rise_and_set(m_rts, h0)
+		nu_rts[i] = day.nu + 360.985647*m_rts[i]
 
-		for i = 0; i < SUN_COUNT; i++ {
+		n = m_rts[i] + spa.Delta_t/86400.0
+		alpha_prime[i] = rts_alpha_delta_prime(day.alpha[:], n)
+		delta_prime[i] = rts_alpha_delta_prime(day.delta[:], n)
 
-			nu_rts[i] = nu + 360.985647*m_rts[i]
+		ev.h_prime[i] = limit_degrees180pm(nu_rts[i] + spa.Longitude - alpha_prime[i])
 
-			n = m_rts[i] + spa.Delta_t/86400.0
-			alpha_prime[i] = rts_alpha_delta_prime(alpha, n)
-			delta_prime[i] = rts_alpha_delta_prime(delta, n)
+		ev.h[i] = rts_sun_altitude(spa.Latitude, delta_prime[i], ev.h_prime[i])
+	}
 
-			h_prime[i] = limit_degrees180pm(nu_rts[i] + spa.Longitude - alpha_prime[i])
+	ev.m[SUN_TRANSIT] = m_rts[SUN_TRANSIT] - ev.h_prime[SUN_TRANSIT]/360.0
 
-			h_rts[i] = rts_sun_altitude(spa.Latitude, delta_prime[i], h_prime[i])
-		}
+	if ev.h0 >= 0 {
+		ev.m[SUN_RISE] = sun_rise_and_set(m_rts[:], ev.h[:], delta_prime[:],
+			spa.Latitude, ev.h_prime[:], h0_prime, SUN_RISE)
 
-		spa.srha = h_prime[SUN_RISE]
-		spa.ssha = h_prime[SUN_SET]
-		spa.sta = h_rts[SUN_TRANSIT]
+		ev.m[SUN_SET] = sun_rise_and_set(m_rts[:], ev.h[:], delta_prime[:],
+			spa.Latitude, ev.h_prime[:], h0_prime, SUN_SET)
+	}
+}
 
-		spa.suntransit = dayfrac_to_local_hr(m_rts[SUN_TRANSIT]-h_prime[SUN_TRANSIT]/360.0,
-			spa.Timezone)
+////////////////////////////////////////////////////////////////////////
+// Calculate Equation of Time (EOT) and Sun Rise, Transit, & Set (RTS)
+////////////////////////////////////////////////////////////////////////
+
+func calculate_eot_and_sun_rise_transit_set(spa *Spa_data) {
+	var day sun_rts_day
+	var ev sun_rts_events
+	var m float64
+	h0_prime := sun_rise_set_altitude(spa)
+
+	m = sun_mean_longitude(spa.jme)
+	spa.eot = eot(m, spa.alpha, spa.Del_psi, spa.Epsilon)
+
+	calculate_sun_rts_day(spa, &day)
+	calculate_sun_rts_events(spa, &day, h0_prime, &ev)
+
+	if ev.h0 >= 0 {
 
-		spa.Sunrise = dayfrac_to_local_hr(sun_rise_and_set(m_rts, h_rts, delta_prime,
-			spa.Latitude, h_prime, h0_prime, SUN_RISE), spa.Timezone)
+		spa.srha = ev.h_prime[SUN_RISE]
+		spa.ssha = ev.h_prime[SUN_SET]
+		spa.sta = ev.h[SUN_TRANSIT]
 
-		spa.Sunset = dayfrac_to_local_hr(sun_rise_and_set(m_rts, h_rts, delta_prime,
-			spa.Latitude, h_prime, h0_prime, SUN_SET), spa.Timezone)
+		spa.suntransit = dayfrac_to_local_hr(ev.m[SUN_TRANSIT], spa.Timezone)
+
+		spa.Sunrise = dayfrac_to_local_hr(ev.m[SUN_RISE], spa.Timezone)
+
+		spa.Sunset = dayfrac_to_local_hr(ev.m[SUN_SET], spa.Timezone)
 
 	} else {
 		spa.srha, spa.ssha, spa.sta, spa.suntransit, spa.Sunrise, spa.Sunset = -99999, -99999, -99999, -99999, -99999, -99999
@@ -1272,50 +1383,71 @@ func calculate_eot_and_sun_rise_transit_set(spa *Spa_data) {
 
 ///////////////////////////////////////////////////////////////////////////////////////////
 // Calculate all SPA parameters and put into structure
-// Note: All inputs values (listed in header file) must already be in structure
+// Note: All inputs values (listed in header file) must already be in structure and valid
 ///////////////////////////////////////////////////////////////////////////////////////////
-func Spa_calculate(spa *Spa_data) int {
-	var result int
+func calculate_all(spa *Spa_data) {
+	calculate_julian_day(spa)
 
-	result = validate_inputs(spa)
+	calculate_geocentric_sun_right_ascension_and_declination(spa)
 
-	if result == 0 {
-		spa.Jd = julian_day(spa.Year, spa.Month, spa.Day, spa.Hour,
-			spa.Minute, spa.Second, spa.Delta_ut1, spa.Timezone)
+	calculate_topocentric_sun_position(spa)
 
-		calculate_geocentric_sun_right_ascension_and_declination(spa)
+	if (spa.Function == SPA_ZA_RTS) || (spa.Function == SPA_ALL) {
+		calculate_eot_and_sun_rise_transit_set(spa)
+	}
+}
 
-		spa.H = observer_hour_angle(spa.nu, spa.Longitude, spa.alpha)
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the observer dependent SPA parameters (parallax, hour angle, refraction, zenith,
+// azimuth and incidence) from the geocentric sun position
+// Note: alpha, delta, nu and R must be already calculated and in structure
+////////////////////////////////////////////////////////////////////////////////////////////////
+func calculate_topocentric_sun_position(spa *Spa_data) {
+	spa.H = observer_hour_angle(spa.nu, spa.Longitude, spa.alpha)
 
-		spa.xi = sun_equatorial_horizontal_parallax(spa.R)
+	spa.xi = sun_equatorial_horizontal_parallax(spa.R)
 
-		right_ascension_parallax_and_topocentric_dec(spa.Latitude, spa.Elevation, spa.xi,
-			spa.H, spa.delta, &(spa.del_alpha), &(spa.delta_prime))
+	right_ascension_parallax_and_topocentric_dec(spa.Latitude, spa.Elevation, spa.xi,
+		spa.H, spa.delta, &(spa.del_alpha), &(spa.delta_prime))
 
+	spa.alpha_prime = topocentric_right_ascension(spa.alpha, spa.del_alpha)
+	spa.h_prime = topocentric_local_hour_angle(spa.H, spa.del_alpha)
 
-		spa.alpha_prime = topocentric_right_ascension(spa.alpha, spa.del_alpha)
-		spa.h_prime = topocentric_local_hour_angle(spa.H, spa.del_alpha)
+	spa.e0 = topocentric_elevation_angle(spa.Latitude, spa.delta_prime, spa.h_prime)
+	spa.del_e = refraction_correction(spa, spa.e0)
+	spa.e = topocentric_elevation_angle_corrected(spa.e0, spa.del_e)
 
-		spa.e0 = topocentric_elevation_angle(spa.Latitude, spa.delta_prime, spa.h_prime)
-		spa.del_e = atmospheric_refraction_correction(spa.Pressure, spa.Temperature,
-			spa.Atmos_refract, spa.e0)
-		spa.e = topocentric_elevation_angle_corrected(spa.e0, spa.del_e)
+	spa.Zenith = topocentric_zenith_angle(spa.e)
+	spa.azimuth_astro = topocentric_azimuth_angle_astro(spa.h_prime, spa.Latitude,
+		spa.delta_prime)
 
-		spa.Zenith = topocentric_zenith_angle(spa.e)
-		spa.azimuth_astro = topocentric_azimuth_angle_astro(spa.h_prime, spa.Latitude,
-			spa.delta_prime)
+	spa.Azimuth = topocentric_azimuth_angle(spa.azimuth_astro)
 
-		spa.Azimuth = topocentric_azimuth_angle(spa.azimuth_astro)
+	spa.Obstructed = spa.e < horizon_elevation(spa, spa.Azimuth)
 
-		if (spa.Function == SPA_ZA_INC) || (spa.Function == SPA_ALL) {
-			spa.Incidence = surface_incidence_angle(spa.Zenith, spa.azimuth_astro,
-				spa.Azm_rotation, spa.Slope)
-		}
+	if (spa.Function == SPA_ZA_INC) || (spa.Function == SPA_ALL) {
+		spa.Incidence = surface_incidence_angle(spa.Zenith, spa.azimuth_astro,
+			spa.Azm_rotation, spa.Slope)
+	}
+}
 
-		if (spa.Function == SPA_ZA_RTS) || (spa.Function == SPA_ALL) {
-			calculate_eot_and_sun_rise_transit_set(spa)
-		}
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters and put into structure
+// Note: All inputs values (listed in header file) must already be in structure
+// Note: The calculation makes no heap allocations and uses no shared mutable state, so it is
+// safe to call concurrently from many goroutines as long as each uses its own structure
+///////////////////////////////////////////////////////////////////////////////////////////
+func Spa_calculate(spa *Spa_data) int {
+	var result int
 
+	result = validate_inputs(spa)
+
+	if result == 0 && apply_earth_orientation(spa) != nil {
+		result = 18
+	}
+
+	if result == 0 {
+		calculate_all(spa)
 	}
 
 	return result
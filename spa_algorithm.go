@@ -0,0 +1,360 @@
+package gosolar
+
+import (
+	"math"
+)
+
+const (
+	GRENA_T0 = 2473459.5 //Julian day of 2060 January 1, 0h, the time origin of Grena's algorithms
+)
+
+// Spa_sun is the geocentric sun position computed by a Spa_algorithm.
+type Spa_sun struct {
+	Alpha   float64 // geocentric (apparent) right ascension [degrees]
+	Delta   float64 // geocentric (apparent) declination [degrees]
+	Nu      float64 // Greenwich (apparent) sidereal time [degrees]
+	R       float64 // earth-sun distance [Astronomical Units, AU]
+	Del_psi float64 // nutation in longitude [degrees], zero when the algorithm ignores nutation
+	Epsilon float64 // obliquity of the ecliptic [degrees]
+}
+
+// Spa_algorithm is a solar position algorithm. Set it as Spa_data.Algorithm to use it instead of
+// the NREL SPA for the geocentric sun position; parallax, refraction, zenith, azimuth, incidence,
+// equation of time and rise/transit/set are then calculated from it exactly as for the SPA, so
+// every output of Spa_calculate keeps its meaning. The SPA intermediate values it does not give
+// (heliocentric earth, geocentric longitude and latitude, nutation in obliquity, aberration) are
+// zero. The moon, planet and eclipse calculations ignore it and always use the SPA.
+type Spa_algorithm interface {
+	// first and last year the algorithm is valid for (a Year outside them is error code 21)
+	Years() (first, last int)
+	// sun position at the Julian day jd (UT) and the Julian ephemeris day jde (TT)
+	Sun(jd, jde float64) Spa_sun
+}
+
+// The accuracies below are the largest angular distance from the SPA sun position (topocentric
+// azimuth and elevation angle) over the valid years, see TestAlgorithm_accuracy.
+
+// Spa_algorithm_spa is the NREL SPA itself (the same as a nil Spa_data.Algorithm), all years
+// from -2000 to 6000, +/-0.0003 degrees.
+type Spa_algorithm_spa struct{}
+
+// Spa_algorithm_psa is the PSA algorithm of Blanco-Muriel et al. (2001) with the coefficients
+// refitted by Blanco et al. (2020), years 2020 to 2050, within 0.01 degrees of the SPA.
+type Spa_algorithm_psa struct{}
+
+// Spa_algorithm_psa2001 is the PSA algorithm with its original coefficients (Blanco-Muriel et
+// al., 2001), years 1999 to 2015, within 0.01 degrees of the SPA.
+type Spa_algorithm_psa2001 struct{}
+
+// Spa_algorithm_grena1 is algorithm 1 of Grena (2012), a harmonic fit of right ascension and
+// declination without Delta T, years 2010 to 2110, within 0.2 degrees of the SPA.
+type Spa_algorithm_grena1 struct{}
+
+// Spa_algorithm_grena2 is algorithm 2 of Grena (2012), the fit of algorithm 1 extended to four
+// harmonics, years 2010 to 2110, within 0.04 degrees of the SPA.
+type Spa_algorithm_grena2 struct{}
+
+// Spa_algorithm_grena3 is algorithm 3 of Grena (2012), a fit of the ecliptic longitude and
+// obliquity, years 2010 to 2110, within 0.01 degrees of the SPA.
+type Spa_algorithm_grena3 struct{}
+
+// Spa_algorithm_grena4 is algorithm 4 of Grena (2012), algorithm 3 with the main nutation term,
+// years 2010 to 2110, within 0.01 degrees of the SPA.
+type Spa_algorithm_grena4 struct{}
+
+// Spa_algorithm_grena5 is algorithm 5 of Grena (2012), algorithm 4 with a longer harmonic
+// series of the longitude and its lunar and planetary perturbations, years 2010 to 2110,
+// right ascension and declination within 0.0027 degrees of the SPA (0.003 degrees topocentric).
+type Spa_algorithm_grena5 struct{}
+
+// Spa_algorithm_michalsky is the Astronomical Almanac algorithm as given by Michalsky (1988),
+// years 1950 to 2050, within 0.015 degrees of the SPA.
+type Spa_algorithm_michalsky struct{}
+
+// Spa_algorithm_noaa is the method of the NOAA solar calculation spreadsheets (after Meeus),
+// years 1901 to 2099, within 0.02 degrees of the SPA.
+type Spa_algorithm_noaa struct{}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Geocentric right ascension and declination of the sun on the ecliptic [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func sun_equatorial(lamda, epsilon float64, alpha, delta *float64) {
+	lamda_rad := deg2rad(lamda)
+	epsilon_rad := deg2rad(epsilon)
+
+	*alpha = limit_degrees(rad2deg(math.Atan2(math.Cos(epsilon_rad)*math.Sin(lamda_rad), math.Cos(lamda_rad))))
+	*delta = rad2deg(math.Asin(math.Sin(epsilon_rad) * math.Sin(lamda_rad)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Earth-sun distance of the Astronomical Almanac low precision formulas [AU]
+// Note: n is the number of days from J2000.0
+///////////////////////////////////////////////////////////////////////////////////////////////
+func sun_distance_almanac(n float64) float64 {
+	g := deg2rad(357.528 + 0.9856003*n)
+
+	return 1.00014 - 0.01671*math.Cos(g) - 0.00014*math.Cos(2*g)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Sun position with the algorithm of structure (the SPA when nil) and put into structure
+//
+// The SPA intermediate values an algorithm does not produce (L, B, theta, beta, x0 to x4,
+// Del_epsilon, epsilon0, del_tau, lamda and nu0) are zeroed, so none is left over from an
+// earlier calculation with the same structure.
+// Note: Jd, jde and jc must be already calculated and in structure
+///////////////////////////////////////////////////////////////////////////////////////////////
+func calculate_algorithm_sun(spa *Spa_data) {
+	sun := spa.Algorithm.Sun(spa.Jd, spa.jde)
+
+	spa.L, spa.B, spa.theta, spa.beta = 0, 0, 0, 0
+	spa.x0, spa.x1, spa.x2, spa.x3, spa.x4 = 0, 0, 0, 0, 0
+	spa.Del_epsilon, spa.epsilon0, spa.del_tau, spa.lamda, spa.nu0 = 0, 0, 0, 0, 0
+
+	spa.alpha, spa.delta = sun.Alpha, sun.Delta
+	spa.nu, spa.R = sun.Nu, sun.R
+	spa.Del_psi, spa.Epsilon = sun.Del_psi, sun.Epsilon
+}
+
+func (Spa_algorithm_spa) Years() (first, last int) {
+	return -2000, 6000
+}
+
+func (Spa_algorithm_spa) Sun(jd, jde float64) Spa_sun {
+	spa := Spa_data{Jd: jd, Delta_t: 86400.0 * (jde - jd)}
+
+	calculate_geocentric_sun_right_ascension_and_declination(&spa)
+
+	return Spa_sun{Alpha: spa.alpha, Delta: spa.delta, Nu: spa.nu, R: spa.R,
+		Del_psi: spa.Del_psi, Epsilon: spa.Epsilon}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// PSA: ecliptic longitude from the mean anomaly and the lunar node, mean sidereal time
+// Note: p holds the 15 coefficients of the published fit, n is the UT days from J2000.0
+///////////////////////////////////////////////////////////////////////////////////////////////
+func psa_sun(p *[15]float64, jd float64) Spa_sun {
+	var sun Spa_sun
+	n := jd - 2451545.0
+	hours := 24.0 * limit_zero2one(jd-0.5)
+
+	omega := p[0] + p[1]*n
+	l := p[2] + p[3]*n
+	g := p[4] + p[5]*n
+	lamda := l + p[6]*math.Sin(g) + p[7]*math.Sin(2*g) + p[8] + p[9]*math.Sin(omega)
+	epsilon := p[10] + p[11]*n + p[12]*math.Cos(omega)
+
+	sun.Epsilon = rad2deg(epsilon)
+	sun_equatorial(rad2deg(lamda), sun.Epsilon, &sun.Alpha, &sun.Delta)
+	sun.Nu = limit_degrees(15.0 * (p[13] + p[14]*n + hours))
+	sun.R = sun_distance_almanac(n)
+
+	return sun
+}
+
+var psa_coefficients = [15]float64{2.267127827, -9.300339267e-4, 4.895036035, 1.720279602e-2,
+	6.239468336, 1.720200135e-2, 3.338320972e-2, 3.497596876e-4, -1.544353226e-4, -8.689729360e-6,
+	4.090904909e-1, -6.213605399e-9, 4.418094944e-5, 6.697096103, 6.570984737e-2}
+
+var psa2001_coefficients = [15]float64{2.1429, -0.0010394594, 4.8950630, 0.017202791698,
+	6.2400600, 0.0172019699, 0.03341607, 0.00034894, -0.0001134, -0.0000203,
+	0.4090928, -6.2140e-9, 0.0000396, 6.6974243242, 0.0657098283}
+
+func (Spa_algorithm_psa) Years() (first, last int) {
+	return 2020, 2050
+}
+
+func (Spa_algorithm_psa) Sun(jd, jde float64) Spa_sun {
+	return psa_sun(&psa_coefficients, jd)
+}
+
+func (Spa_algorithm_psa2001) Years() (first, last int) {
+	return 1999, 2015
+}
+
+func (Spa_algorithm_psa2001) Sun(jd, jde float64) Spa_sun {
+	return psa_sun(&psa2001_coefficients, jd)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Grena: t and te are the UT and TT days from 2060 January 1, 0h; right ascension, declination,
+// longitude and obliquity are in radians, the hour angle term gives the sidereal time
+///////////////////////////////////////////////////////////////////////////////////////////////
+func grena_sun(jd, jde, alpha, delta, del_lamda, epsilon float64) Spa_sun {
+	t := jd - GRENA_T0
+
+	return Spa_sun{
+		Alpha:   limit_degrees(rad2deg(alpha)),
+		Delta:   rad2deg(delta),
+		Nu:      limit_degrees(rad2deg(1.7528311 + 6.300388099*t + 0.92*del_lamda)),
+		R:       sun_distance_almanac(jde - 2451545.0),
+		Del_psi: rad2deg(del_lamda),
+		Epsilon: rad2deg(epsilon),
+	}
+}
+
+func grena_harmonics(w, te float64, s, c *[5]float64) {
+	wte := w * te
+
+	for k := 1; k < len(s); k++ {
+		s[k], c[k] = math.Sincos(float64(k) * wte)
+	}
+}
+
+func (Spa_algorithm_grena1) Years() (first, last int) {
+	return 2010, 2110
+}
+
+func (Spa_algorithm_grena1) Sun(jd, jde float64) Spa_sun {
+	var s, c [5]float64
+	t := jd - GRENA_T0
+
+	grena_harmonics(0.017202786, t, &s, &c)
+
+	alpha := -1.38880 + 1.72027920e-2*t + 3.199e-2*s[1] - 2.65e-3*c[1] + 4.050e-2*s[2] + 1.525e-2*c[2]
+	delta := 6.57e-3 + 7.347e-2*s[1] - 3.9919e-1*c[1] + 7.3e-4*s[2] - 6.60e-3*c[2]
+
+	sun := grena_sun(jd, jd, alpha, delta, 0, deg2rad(23.4393))
+	sun.Nu = limit_degrees(rad2deg(1.75283 + 6.3003881*t))
+
+	return sun
+}
+
+func (Spa_algorithm_grena2) Years() (first, last int) {
+	return 2010, 2110
+}
+
+func (Spa_algorithm_grena2) Sun(jd, jde float64) Spa_sun {
+	var s, c [5]float64
+	te := jde - GRENA_T0
+
+	grena_harmonics(0.017202786, te, &s, &c)
+
+	alpha := -1.38880 + 1.72027920e-2*te + 3.199e-2*s[1] - 2.65e-3*c[1] + 4.050e-2*s[2] + 1.525e-2*c[2] +
+		1.33e-3*s[3] + 3.8e-4*c[3] + 7.3e-4*s[4] + 6.2e-4*c[4]
+	delta := 6.57e-3 + 7.347e-2*s[1] - 3.9919e-1*c[1] + 7.3e-4*s[2] - 6.60e-3*c[2] +
+		1.50e-3*s[3] - 2.58e-3*c[3] + 6e-5*s[4] - 1.3e-4*c[4]
+
+	sun := grena_sun(jd, jde, alpha, delta, 0, deg2rad(23.4393))
+	sun.Nu = limit_degrees(rad2deg(1.75283 + 6.3003881*(jd-GRENA_T0)))
+
+	return sun
+}
+
+func (Spa_algorithm_grena3) Years() (first, last int) {
+	return 2010, 2110
+}
+
+func (Spa_algorithm_grena3) Sun(jd, jde float64) Spa_sun {
+	var alpha, delta float64
+	te := jde - GRENA_T0
+	wte := 0.0172019715 * te
+
+	lamda := -1.388803 + 1.720279216e-2*te + 3.3366e-2*math.Sin(wte-0.06172) + 3.53e-4*math.Sin(2*wte-0.1163)
+	epsilon := 4.089567e-1 - 6.19e-9*te
+
+	sun_equatorial(rad2deg(lamda), rad2deg(epsilon), &alpha, &delta)
+
+	return grena_sun(jd, jde, deg2rad(alpha), deg2rad(delta), 0, epsilon)
+}
+
+func (Spa_algorithm_grena4) Years() (first, last int) {
+	return 2010, 2110
+}
+
+func (Spa_algorithm_grena4) Sun(jd, jde float64) Spa_sun {
+	var alpha, delta float64
+	te := jde - GRENA_T0
+	wte := 0.0172019715 * te
+
+	l := 1.752790 + 1.720279216e-2*te + 3.3366e-2*math.Sin(wte-0.06172) + 3.53e-4*math.Sin(2*wte-0.1163)
+	nu := 9.282e-4*te - 0.8
+	del_lamda := 8.34e-5 * math.Sin(nu)
+	lamda := l + PI + del_lamda
+	epsilon := 4.089567e-1 - 6.19e-9*te + 4.46e-5*math.Cos(nu)
+
+	sun_equatorial(rad2deg(lamda), rad2deg(epsilon), &alpha, &delta)
+
+	return grena_sun(jd, jde, deg2rad(alpha), deg2rad(delta), del_lamda, epsilon)
+}
+
+func (Spa_algorithm_grena5) Years() (first, last int) {
+	return 2010, 2110
+}
+
+func (Spa_algorithm_grena5) Sun(jd, jde float64) Spa_sun {
+	var s, c [5]float64
+	var alpha, delta float64
+	te := jde - GRENA_T0
+
+	grena_harmonics(0.0172019715, te, &s, &c)
+
+	l := 1.7527901 + 1.7202792159e-2*te + 3.33024e-2*s[1] - 2.0582e-3*c[1] + 3.512e-4*s[2] - 4.07e-5*c[2] +
+		5.2e-6*s[3] - 9e-7*c[3] -
+		8.23e-5*s[1]*math.Sin(2.92e-5*te) + 1.27e-5*math.Sin(1.49e-3*te-2.337) +
+		1.21e-5*math.Sin(4.31e-3*te+3.065) + 2.33e-5*math.Sin(1.076e-2*te-1.533) +
+		3.49e-5*math.Sin(1.575e-2*te-2.358) + 2.67e-5*math.Sin(2.152e-2*te+0.074) +
+		1.28e-5*math.Sin(3.152e-2*te+1.547) + 3.14e-5*math.Sin(2.1277e-1*te-0.488)
+	nu := 9.282e-4*te - 0.8
+	del_lamda := 8.34e-5 * math.Sin(nu)
+	lamda := l + PI + del_lamda
+	epsilon := 4.089567e-1 - 6.19e-9*te + 4.46e-5*math.Cos(nu)
+
+	sun_equatorial(rad2deg(lamda), rad2deg(epsilon), &alpha, &delta)
+
+	return grena_sun(jd, jde, deg2rad(alpha), deg2rad(delta), del_lamda, epsilon)
+}
+
+func (Spa_algorithm_michalsky) Years() (first, last int) {
+	return 1950, 2050
+}
+
+func (Spa_algorithm_michalsky) Sun(jd, jde float64) Spa_sun {
+	var sun Spa_sun
+	n := jd - 2451545.0
+	hours := 24.0 * limit_zero2one(jd-0.5)
+
+	l := limit_degrees(280.460 + 0.9856474*n)
+	g := deg2rad(limit_degrees(357.528 + 0.9856003*n))
+	lamda := l + 1.915*math.Sin(g) + 0.020*math.Sin(2*g)
+
+	sun.Epsilon = 23.439 - 0.0000004*n
+	sun_equatorial(lamda, sun.Epsilon, &sun.Alpha, &sun.Delta)
+	sun.Nu = limit_degrees(15.0 * (6.697375 + 0.0657098242*n + hours))
+	sun.R = sun_distance_almanac(n)
+
+	return sun
+}
+
+func (Spa_algorithm_noaa) Years() (first, last int) {
+	return 1901, 2099
+}
+
+func (Spa_algorithm_noaa) Sun(jd, jde float64) Spa_sun {
+	var sun Spa_sun
+	jc := julian_century(jd)
+	minutes := 1440.0 * limit_zero2one(jd-0.5)
+
+	l0 := limit_degrees(280.46646 + jc*(36000.76983+jc*0.0003032))
+	m := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	e := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+	m_rad := deg2rad(m)
+	c := math.Sin(m_rad)*(1.914602-jc*(0.004817+0.000014*jc)) + math.Sin(2*m_rad)*(0.019993-0.000101*jc) +
+		math.Sin(3*m_rad)*0.000289
+	omega := deg2rad(125.04 - 1934.136*jc)
+	lamda := l0 + c - 0.00569 - 0.00478*math.Sin(omega)
+	epsilon0 := 23.0 + (26.0+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60.0)/60.0
+
+	sun.Epsilon = epsilon0 + 0.00256*math.Cos(omega)
+	sun_equatorial(lamda, sun.Epsilon, &sun.Alpha, &sun.Delta)
+	sun.R = 1.000001018 * (1 - e*e) / (1 + e*math.Cos(deg2rad(m)+deg2rad(c)))
+
+	// the spreadsheet's hour angle comes from the true solar time (UT plus its equation of time)
+	y := math.Pow(math.Tan(deg2rad(sun.Epsilon/2)), 2)
+	l0_rad := deg2rad(l0)
+	eot := 4.0 * rad2deg(y*math.Sin(2*l0_rad)-2*e*math.Sin(m_rad)+4*e*y*math.Sin(m_rad)*math.Cos(2*l0_rad)-
+		0.5*y*y*math.Sin(4*l0_rad)-1.25*e*e*math.Sin(2*m_rad))
+	sun.Nu = limit_degrees((minutes+eot)/4.0 - 180.0 + sun.Alpha)
+
+	return sun
+}
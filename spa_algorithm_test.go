@@ -0,0 +1,111 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var algorithm_tests = []struct {
+	name       string
+	algorithm  Spa_algorithm
+	accuracy   float64 // documented largest distance from the SPA [degrees]
+	geocentric float64 // documented largest distance of the right ascension and declination, 0 if none [degrees]
+}{
+	{"spa", Spa_algorithm_spa{}, 1e-9, 1e-9},
+	{"psa", Spa_algorithm_psa{}, 0.01, 0},
+	{"psa2001", Spa_algorithm_psa2001{}, 0.01, 0},
+	{"grena1", Spa_algorithm_grena1{}, 0.2, 0},
+	{"grena2", Spa_algorithm_grena2{}, 0.04, 0},
+	{"grena3", Spa_algorithm_grena3{}, 0.01, 0},
+	{"grena4", Spa_algorithm_grena4{}, 0.01, 0},
+	{"grena5", Spa_algorithm_grena5{}, 0.003, 0.0027},
+	{"michalsky", Spa_algorithm_michalsky{}, 0.015, 0},
+	{"noaa", Spa_algorithm_noaa{}, 0.02, 0},
+}
+
+func TestAlgorithm_accuracy(t *testing.T) {
+	const samples = 20000
+
+	for _, tt := range algorithm_tests {
+		var max_error, max_geocentric float64
+		first, last := tt.algorithm.Years()
+		jd_first := julian_day(first, 1, 1, 0, 0, 0, 0, 0)
+		jd_last := julian_day(last, 12, 31, 0, 0, 0, 0, 0)
+
+		for i := 0; i < samples; i++ {
+			jd := jd_first + float64(i)*(jd_last-jd_first)/samples + float64(i%7)*0.37
+			spa := Spa_data{
+				Jd:            jd,
+				Delta_t:       Spa_delta_t(2000.0 + (jd-2451545.0)/365.25),
+				Latitude:      float64(i%140) - 70,
+				Longitude:     float64((i*37)%360) - 180,
+				Pressure:      1010,
+				Temperature:   10,
+				Atmos_refract: 0.5667,
+			}
+			alt := spa
+			alt.Algorithm = tt.algorithm
+
+			calculate_geocentric_sun_right_ascension_and_declination(&spa)
+			calculate_topocentric_sun_position(&spa)
+			calculate_geocentric_sun_right_ascension_and_declination(&alt)
+			calculate_topocentric_sun_position(&alt)
+
+			max_error = math.Max(max_error, angular_separation(spa.Azimuth, spa.e0, alt.Azimuth, alt.e0))
+			max_geocentric = math.Max(max_geocentric, angular_separation(spa.alpha, spa.delta, alt.alpha, alt.delta))
+		}
+
+		t.Logf("%s (%d to %d): max error %.6f degrees, %.6f degrees geocentric", tt.name, first, last, max_error,
+			max_geocentric)
+		if max_error > tt.accuracy {
+			t.Errorf("%s exceeds its accuracy of %g degrees: %.6f", tt.name, tt.accuracy, max_error)
+		}
+		if tt.geocentric > 0 && max_geocentric > tt.geocentric {
+			t.Errorf("%s exceeds its geocentric accuracy of %g degrees: %.6f", tt.name, tt.geocentric, max_geocentric)
+		}
+	}
+}
+
+func TestAlgorithm_unproduced(t *testing.T) {
+	spa := example_spa_data()
+	Spa_calculate(&spa)
+
+	// the values of the earlier SPA calculation must not be left over
+	spa.Algorithm = Spa_algorithm_michalsky{}
+	if code := Spa_calculate(&spa); code != 0 {
+		t.Fatalf("error code %d", code)
+	}
+	if spa.L != 0 || spa.B != 0 || spa.theta != 0 || spa.Del_epsilon != 0 || spa.lamda != 0 || spa.nu0 != 0 {
+		t.Errorf("L %v, B %v, theta %v, Del_epsilon %v, lamda %v, nu0 %v, want 0", spa.L, spa.B, spa.theta,
+			spa.Del_epsilon, spa.lamda, spa.nu0)
+	}
+	if spa.Epsilon == 0 || spa.R == 0 {
+		t.Errorf("Epsilon %v, R %v, want the values of the algorithm", spa.Epsilon, spa.R)
+	}
+}
+
+func TestAlgorithm_years(t *testing.T) {
+	for _, tt := range algorithm_tests {
+		first, last := tt.algorithm.Years()
+
+		spa := example_spa_data()
+		spa.Algorithm = tt.algorithm
+		spa.Year = first
+		if code := Spa_calculate(&spa); code != 0 {
+			t.Errorf("%s: year %d gives error code %d", tt.name, first, code)
+		}
+
+		if last >= 6000 {
+			continue
+		}
+
+		spa.Year = last + 1
+		if code := Spa_calculate(&spa); code != 21 {
+			t.Errorf("%s: year %d gives error code %d, want 21", tt.name, last+1, code)
+		}
+		if _, err := Spa_compute(spa); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: year %d gives Spa_compute error %v, want ErrInvalidInput", tt.name, last+1, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package gosolar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Spa_observer holds the site dependent inputs of Spa_data (same units and valid ranges).
+type Spa_observer struct {
+	Longitude       float64 // Observer longitude (negative west of Greenwich) [degrees]
+	Latitude        float64 // Observer latitude (negative south of equator) [degrees]
+	Elevation       float64 // Observer elevation [meters]
+	Observer_height float64 // Observer eye height above the local horizon [meters]
+	Pressure        float64 // Annual average local pressure [millibars]
+	Temperature     float64 // Annual average local temperature [degrees Celsius]
+	Slope           float64 // Surface slope (measured from the horizontal plane) [degrees]
+	Azm_rotation    float64 // Surface azimuth rotation (measured from south, negative east) [degrees]
+	Atmos_refract   float64 // Atmospheric refraction at sunrise and sunset [degrees]
+}
+
+// Spa_batch holds the sun position for every combination of Times and Observers in columnar
+// form. The geocentric columns have one value per time; the topocentric columns have one value
+// per time and observer, stored at Index(time, observer).
+type Spa_batch struct {
+	Times     []time.Time
+	Observers []Spa_observer
+
+	//----------------Geocentric OUTPUT VALUES (per time)----------------
+
+	Jd      []float64 //Julian day
+	Delta_t []float64 //Delta T used (estimated when Delta_t_auto is set) [seconds]
+	R       []float64 //earth radius vector [Astronomical Units, AU]
+	Del_psi []float64 //nutation longitude [degrees]
+	Epsilon []float64 //ecliptic true obliquity  [degrees]
+	Nu      []float64 //Greenwich sidereal time [degrees]
+	Alpha   []float64 //geocentric sun right ascension [degrees]
+	Delta   []float64 //geocentric sun declination [degrees]
+
+	//-------------Topocentric OUTPUT VALUES (per time and observer)-------------
+
+	Alpha_prime   []float64 //topocentric sun right ascension [degrees]
+	Delta_prime   []float64 //topocentric sun declination [degrees]
+	H_prime       []float64 //topocentric local hour angle [degrees]
+	E0            []float64 //topocentric elevation angle (uncorrected) [degrees]
+	Del_e         []float64 //atmospheric refraction correction [degrees]
+	Zenith        []float64 //topocentric zenith angle [degrees]
+	Azimuth_astro []float64 //topocentric azimuth angle (westward from south) [for astronomers]
+	Azimuth       []float64 //topocentric azimuth angle (eastward from north) [for navigators and solar radiation]
+	Incidence     []float64 //surface incidence angle [degrees], zero unless Function is SPA_ZA_INC or SPA_ALL
+	Obstructed    []bool    //sun center (refraction corrected) is below the horizon profile
+}
+
+// Index returns the position of time i and observer j in the topocentric columns.
+func (b *Spa_batch) Index(i, j int) int {
+	return i*len(b.Observers) + j
+}
+
+func spa_set_observer(spa *Spa_data, obs *Spa_observer) {
+	spa.Longitude = obs.Longitude
+	spa.Latitude = obs.Latitude
+	spa.Elevation = obs.Elevation
+	spa.Observer_height = obs.Observer_height
+	spa.Pressure = obs.Pressure
+	spa.Temperature = obs.Temperature
+	spa.Slope = obs.Slope
+	spa.Azm_rotation = obs.Azm_rotation
+	spa.Atmos_refract = obs.Atmos_refract
+}
+
+// field errors of the Spa_data fields that come from a Spa_observer
+func observer_errors(errs []*Spa_range_error) []*Spa_range_error {
+	var field_errs []*Spa_range_error
+
+	for _, err := range errs {
+		switch err.Field {
+		case "Longitude", "Latitude", "Elevation", "Observer_height", "Pressure", "Temperature",
+			"Slope", "Azm_rotation", "Atmos_refract":
+			field_errs = append(field_errs, err)
+		}
+	}
+
+	return field_errs
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the sun position for every time and observer
+//
+// The Earth ephemeris (VSOP summation, nutation, obliquity and sidereal time) depends only on
+// the instant, so it is calculated once per time and only the topocentric step is repeated per
+// observer. Delta_ut1, Delta_t, Delta_t_auto, Earth_orientation, Refraction, Horizon, Precision,
+// Algorithm and Function are taken from in; its date, time and observer fields are ignored.
+// Rise/transit/set values are not part of the batch (SPA_ZA_RTS is treated as SPA_ZA and SPA_ALL
+// as SPA_ZA_INC).
+// Every time and every observer is validated before anything is calculated; the error joins one
+// error per invalid row ("times[i]: ..." or "observers[j]: ...", each matching ErrInvalidInput).
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_compute_batch(times []time.Time, observers []Spa_observer, in Spa_data) (*Spa_batch, error) {
+	var spa Spa_data
+	var i, j, k int
+	nt, no := len(times), len(observers)
+
+	if in.Function == SPA_ZA_RTS {
+		in.Function = SPA_ZA
+	} else if in.Function == SPA_ALL {
+		in.Function = SPA_ZA_INC
+	}
+
+	// every row is checked on its own: times (with in) for all but the observer fields, and
+	// observers for their fields only
+	var errs []error
+	for i = 0; i < nt; i++ {
+		spa = in
+		spa_set_time(&spa, times[i].UTC())
+		spa_set_observer(&spa, &Spa_observer{})
+		if err := Spa_validate(&spa); err != nil {
+			errs = append(errs, fmt.Errorf("times[%d]: %w", i, err))
+		}
+	}
+	for j = 0; j < no; j++ {
+		spa = in
+		spa_set_observer(&spa, &observers[j])
+		if field_errs := observer_errors(validate_inputs_all(&spa)); len(field_errs) > 0 {
+			errs = append(errs, fmt.Errorf("observers[%d]: %w", j, &Spa_validation_error{Errors: field_errs}))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	b := &Spa_batch{
+		Times:     times,
+		Observers: observers,
+
+		Jd:      make([]float64, nt),
+		Delta_t: make([]float64, nt),
+		R:       make([]float64, nt),
+		Del_psi: make([]float64, nt),
+		Epsilon: make([]float64, nt),
+		Nu:      make([]float64, nt),
+		Alpha:   make([]float64, nt),
+		Delta:   make([]float64, nt),
+
+		Alpha_prime:   make([]float64, nt*no),
+		Delta_prime:   make([]float64, nt*no),
+		H_prime:       make([]float64, nt*no),
+		E0:            make([]float64, nt*no),
+		Del_e:         make([]float64, nt*no),
+		Zenith:        make([]float64, nt*no),
+		Azimuth_astro: make([]float64, nt*no),
+		Azimuth:       make([]float64, nt*no),
+		Incidence:     make([]float64, nt*no),
+		Obstructed:    make([]bool, nt*no),
+	}
+
+	for i = 0; i < nt; i++ {
+		geo := in
+		spa_set_time(&geo, times[i].UTC())
+
+		if err := apply_earth_orientation(&geo); err != nil {
+			return nil, fmt.Errorf("times[%d]: %w", i, err)
+		}
+
+		calculate_julian_day(&geo)
+
+		calculate_geocentric_sun_right_ascension_and_declination(&geo)
+
+		b.Jd[i], b.Delta_t[i] = geo.Jd, geo.Delta_t
+		b.R[i], b.Del_psi[i], b.Epsilon[i] = geo.R, geo.Del_psi, geo.Epsilon
+		b.Nu[i], b.Alpha[i], b.Delta[i] = geo.nu, geo.alpha, geo.delta
+
+		for j = 0; j < no; j++ {
+			spa = geo
+			spa_set_observer(&spa, &observers[j])
+
+			calculate_topocentric_sun_position(&spa)
+
+			k = b.Index(i, j)
+			b.Alpha_prime[k], b.Delta_prime[k], b.H_prime[k] = spa.alpha_prime, spa.delta_prime, spa.h_prime
+			b.E0[k], b.Del_e[k] = spa.e0, spa.del_e
+			b.Zenith[k], b.Azimuth_astro[k], b.Azimuth[k] = spa.Zenith, spa.azimuth_astro, spa.Azimuth
+			b.Incidence[k], b.Obstructed[k] = spa.Incidence, spa.Obstructed
+		}
+	}
+
+	return b, nil
+}
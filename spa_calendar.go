@@ -0,0 +1,65 @@
+package gosolar
+
+import (
+	"github.com/Spectrafy/gosolar/astrotime"
+)
+
+const (
+	SPA_CALENDAR_GREGORIAN_SWITCH = astrotime.CALENDAR_GREGORIAN_SWITCH //Julian calendar until 1582 October 4, Gregorian from 1582 October 15 (NREL SPA)
+	SPA_CALENDAR_GREGORIAN        = astrotime.CALENDAR_GREGORIAN        //proleptic Gregorian calendar (as package time)
+	SPA_CALENDAR_JULIAN           = astrotime.CALENDAR_JULIAN           //proleptic Julian calendar
+	SPA_CALENDAR_COUNT            = 3
+)
+
+// the ten days dropped by the Gregorian reform (1582 October 5 to 14)
+const (
+	GREGORIAN_SWITCH_YEAR      = 1582
+	GREGORIAN_SWITCH_MONTH     = 10
+	GREGORIAN_SWITCH_FIRST_DAY = 5
+	GREGORIAN_SWITCH_LAST_DAY  = 14
+)
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Is the (astronomically numbered) year a leap year in the calendar
+///////////////////////////////////////////////////////////////////////////////////////////////
+func is_leap_year(year, calendar int) bool {
+	gregorian := calendar == SPA_CALENDAR_GREGORIAN ||
+		(calendar == SPA_CALENDAR_GREGORIAN_SWITCH && year > GREGORIAN_SWITCH_YEAR)
+
+	if gregorian {
+		return (year%4 == 0) && ((year%100 != 0) || (year%400 == 0))
+	}
+
+	return year%4 == 0
+}
+
+func days_in_month(year, month, calendar int) int {
+	switch month {
+	case 2:
+		if is_leap_year(year, calendar) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+
+	return 31
+}
+
+func is_gregorian_switch_month(year, month, calendar int) bool {
+	return (calendar == SPA_CALENDAR_GREGORIAN_SWITCH) && (year == GREGORIAN_SWITCH_YEAR) &&
+		(month == GREGORIAN_SWITCH_MONTH)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Does the date exist in the calendar (month 1 to 12 assumed)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func calendar_date_exists(year, month, day, calendar int) bool {
+	if is_gregorian_switch_month(year, month, calendar) &&
+		(day >= GREGORIAN_SWITCH_FIRST_DAY) && (day <= GREGORIAN_SWITCH_LAST_DAY) {
+		return false
+	}
+
+	return (day >= 1) && (day <= days_in_month(year, month, calendar))
+}
@@ -0,0 +1,108 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalendar_strict_date(t *testing.T) {
+	tests := []struct {
+		year, month, day, calendar int
+		ok                         bool
+	}{
+		{2003, 2, 31, SPA_CALENDAR_GREGORIAN_SWITCH, false},
+		{1900, 2, 29, SPA_CALENDAR_GREGORIAN_SWITCH, false},
+		{1900, 2, 29, SPA_CALENDAR_JULIAN, true},
+		{2000, 2, 29, SPA_CALENDAR_GREGORIAN, true},
+		{1500, 2, 29, SPA_CALENDAR_GREGORIAN_SWITCH, true},
+		{1500, 2, 29, SPA_CALENDAR_GREGORIAN, false},
+		{-1, 2, 29, SPA_CALENDAR_GREGORIAN, false},
+		{0, 2, 29, SPA_CALENDAR_GREGORIAN, true},
+		{2003, 4, 31, SPA_CALENDAR_GREGORIAN, false},
+		{1582, 10, 4, SPA_CALENDAR_GREGORIAN_SWITCH, true},
+		{1582, 10, 10, SPA_CALENDAR_GREGORIAN_SWITCH, false},
+		{1582, 10, 15, SPA_CALENDAR_GREGORIAN_SWITCH, true},
+		{1582, 10, 10, SPA_CALENDAR_GREGORIAN, true},
+		{1582, 10, 10, SPA_CALENDAR_JULIAN, true},
+	}
+
+	for _, tt := range tests {
+		spa := example_spa_data()
+		spa.Year, spa.Month, spa.Day, spa.Calendar = tt.year, tt.month, tt.day, tt.calendar
+		spa.Strict_date = true
+
+		code := Spa_calculate(&spa)
+		if tt.ok && code != 0 {
+			t.Errorf("%d-%02d-%02d (calendar %d): error code %d", tt.year, tt.month, tt.day, tt.calendar, code)
+		}
+		if !tt.ok && code != 3 {
+			t.Errorf("%d-%02d-%02d (calendar %d): error code %d, want 3", tt.year, tt.month, tt.day, tt.calendar, code)
+		}
+
+		spa.Strict_date = false
+		if code := Spa_calculate(&spa); code != 0 {
+			t.Errorf("%d-%02d-%02d (calendar %d) not strict: error code %d", tt.year, tt.month, tt.day, tt.calendar, code)
+		}
+	}
+
+	spa := example_spa_data()
+	spa.Month, spa.Day, spa.Strict_date = 2, 31, true
+	var range_err *Spa_range_error
+	if _, err := Spa_compute(spa); !errors.As(err, &range_err) || range_err.Field != "Day" || range_err.Max != 28 {
+		t.Errorf("February 31: error %v, want a Day range error up to 28", err)
+	}
+}
+
+func TestCalendar_invalid(t *testing.T) {
+	for _, calendar := range []int{-1, SPA_CALENDAR_COUNT} {
+		spa := example_spa_data()
+		spa.Calendar = calendar
+		if code := Spa_calculate(&spa); code != 22 {
+			t.Errorf("calendar %d: error code %d, want 22", calendar, code)
+		}
+	}
+}
+
+func TestCalendar_julian_day(t *testing.T) {
+	tests := []struct {
+		year, month, day, calendar int
+		jd                         float64
+	}{
+		{1582, 10, 4, SPA_CALENDAR_GREGORIAN_SWITCH, 2299159.5},
+		{1582, 10, 15, SPA_CALENDAR_GREGORIAN_SWITCH, 2299160.5},
+		{1582, 10, 5, SPA_CALENDAR_JULIAN, 2299160.5},
+		{1582, 10, 15, SPA_CALENDAR_GREGORIAN, 2299160.5},
+		{1582, 10, 4, SPA_CALENDAR_GREGORIAN, 2299149.5},
+		{2000, 1, 1, SPA_CALENDAR_GREGORIAN, 2451544.5},
+		{2000, 1, 1, SPA_CALENDAR_JULIAN, 2451557.5},
+		{-2000, 3, 1, SPA_CALENDAR_JULIAN, 990617.5},
+		{-2000, 3, 1, SPA_CALENDAR_GREGORIAN_SWITCH, 990617.5},
+		{-2000, 3, 1, SPA_CALENDAR_GREGORIAN, 990634.5},
+	}
+
+	for _, tt := range tests {
+		jd := julian_day_calendar(tt.year, tt.month, tt.day, 0, 0, 0, 0, 0, tt.calendar)
+		if jd != tt.jd {
+			t.Errorf("%d-%02d-%02d (calendar %d): jd %.1f, want %.1f", tt.year, tt.month, tt.day, tt.calendar, jd, tt.jd)
+		}
+	}
+}
+
+func TestCalendar_time_proleptic(t *testing.T) {
+	for _, year := range []int{-1999, -500, 0, 1000, 1582, 1583, 2024} {
+		tm := time.Date(year, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		spa := example_spa_data()
+		spa.Function = SPA_ZA
+		if code := Spa_calculate_time(tm, &spa); code != 0 {
+			t.Fatalf("year %d: error code %d", year, code)
+		}
+
+		want := 2440587.5 + float64(tm.Unix())/86400.0
+		if math.Abs(spa.Jd-want) > 1e-6 {
+			t.Errorf("year %d: jd %.6f, want %.6f", year, spa.Jd, want)
+		}
+	}
+}
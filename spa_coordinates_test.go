@@ -0,0 +1,64 @@
+package gosolar
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Spectrafy/gosolar/coordinates"
+)
+
+func angle_diff(a, b float64) float64 {
+	return math.Abs(math.Remainder(a-b, 360.0))
+}
+
+func TestCoordinates_sun_chain(t *testing.T) {
+	obs := Spa_observer{Longitude: -105.1786, Latitude: 39.742476, Elevation: 1830.14,
+		Pressure: 820, Temperature: 11, Atmos_refract: 0.5667}
+	atm := Spa_atmosphere{Pressure: obs.Pressure, Temperature: obs.Temperature, Elevation: obs.Elevation,
+		Latitude: obs.Latitude, Atmos_refract: obs.Atmos_refract}
+
+	for _, model := range []Spa_refraction{nil, Spa_refraction_nrel{}, Spa_refraction_bennett{}} {
+		refraction := Spa_atmosphere_refraction{Model: model, Atmosphere: atm}
+
+		for hour := 0; hour < 24; hour += 3 {
+			res, err := Spa_compute(Spa_data{Year: 2003, Month: 10, Day: 17, Hour: hour,
+				Timezone: -7, Delta_t: 67, Longitude: obs.Longitude, Latitude: obs.Latitude,
+				Elevation: obs.Elevation, Pressure: obs.Pressure, Temperature: obs.Temperature,
+				Atmos_refract: obs.Atmos_refract, Refraction: model, Function: SPA_ZA})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			eq := coordinates.Ecliptic_to_equatorial(coordinates.Ecliptic{Longitude: res.Lamda, Latitude: res.Beta},
+				res.Epsilon)
+			if angle_diff(eq.Right_ascension, res.Alpha) > 1e-9 || math.Abs(eq.Declination-res.Delta) > 1e-9 {
+				t.Errorf("hour %d: equatorial %+v, want %.9f, %.9f", hour, eq, res.Alpha, res.Delta)
+			}
+
+			lst := res.Nu + obs.Longitude
+			topo := coordinates.Topocentric_hour_angle(coordinates.Equatorial_to_hour_angle(eq, lst),
+				coordinates.Parallax(res.R), obs.Latitude, obs.Elevation)
+			if angle_diff(topo.Hour_angle, res.H_prime) > 1e-9 || math.Abs(topo.Declination-res.Delta_prime) > 1e-9 {
+				t.Errorf("hour %d: topocentric %+v, want %.9f, %.9f", hour, topo, res.H_prime, res.Delta_prime)
+			}
+
+			topo_eq := coordinates.Topocentric_equatorial(eq, lst, coordinates.Parallax(res.R), obs.Latitude,
+				obs.Elevation)
+			if angle_diff(topo_eq.Right_ascension, res.Alpha_prime) > 1e-9 {
+				t.Errorf("hour %d: topocentric right ascension %.9f, want %.9f", hour, topo_eq.Right_ascension,
+					res.Alpha_prime)
+			}
+
+			hz := coordinates.Refract(coordinates.Hour_angle_to_horizontal(topo, obs.Latitude), refraction)
+			if angle_diff(hz.Azimuth, res.Azimuth) > 1e-9 || math.Abs(90-hz.Elevation-res.Zenith) > 1e-9 {
+				t.Errorf("hour %d: horizontal %+v, want azimuth %.9f zenith %.9f", hour, hz, res.Azimuth, res.Zenith)
+			}
+
+			if res.E > 0 {
+				if e0 := coordinates.Unrefract(hz, refraction).Elevation; math.Abs(e0-res.E0) > 1e-6 {
+					t.Errorf("hour %d: unrefracted elevation %.9f, want %.9f", hour, e0, res.E0)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package gosolar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	SPA_CROSSING_ELEVATION = iota //target is the topocentric elevation angle (refraction corrected)
+	SPA_CROSSING_AZIMUTH          //target is the topocentric azimuth angle (eastward from north)
+	SPA_CROSSING_HORIZON          //target is the elevation angle above the horizon profile (Horizon)
+)
+
+const (
+	SPA_CROSSING_RISING  = iota //elevation increasing, or azimuth increasing (clockwise)
+	SPA_CROSSING_SETTING        //elevation decreasing, or azimuth decreasing
+)
+
+const (
+	SPA_CROSSING_STEP      = 10 * time.Minute //sampling step used to bracket the crossings
+	SPA_CROSSING_TOLERANCE = time.Second      //default time tolerance of a crossing
+)
+
+// Spa_crossing is one time the sun reaches the target elevation or azimuth.
+type Spa_crossing struct {
+	Time      time.Time // time of the crossing (in the location of the window start)
+	Direction int       // SPA_CROSSING_RISING or SPA_CROSSING_SETTING
+	Elevation float64   // topocentric elevation angle (refraction corrected) at Time [degrees]
+	Azimuth   float64   // topocentric azimuth angle (eastward from north) at Time [degrees]
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the topocentric sun position at the instant t from the inputs in
+///////////////////////////////////////////////////////////////////////////////////////////////
+func spa_sun_position_at(t time.Time, in *Spa_data, spa *Spa_data) error {
+	*spa = *in
+	spa_set_time(spa, t.UTC())
+
+	if err := apply_earth_orientation(spa); err != nil {
+		return err
+	}
+
+	calculate_julian_day(spa)
+	calculate_geocentric_sun_right_ascension_and_declination(spa)
+	calculate_topocentric_sun_position(spa)
+
+	return nil
+}
+
+// signed distance of the sun from the target, wrapped to -180..180 for azimuth
+func spa_crossing_offset(spa *Spa_data, kind int, target float64) float64 {
+	if kind == SPA_CROSSING_AZIMUTH {
+		return limit_degrees180pm(spa.Azimuth - target)
+	}
+	if kind == SPA_CROSSING_HORIZON {
+		return spa.e - horizon_elevation(spa, spa.Azimuth) - target
+	}
+
+	return spa.e - target
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Find all times in [start, end] when the sun reaches the target elevation or azimuth
+//
+// The window is sampled every SPA_CROSSING_STEP and every sign change of the distance from
+// the target is bisected until the bracket is shorter than tolerance (SPA_CROSSING_TOLERANCE
+// when zero). An azimuth offset that flips through +/-180 degrees is the opposite direction,
+// not a crossing, and is skipped. Elevation extremes within one step of the target (the sun
+// grazing it near transit) may be missed.
+// Note: target is in degrees, -90 to 90 for elevation (also above the horizon profile) or 0
+// to 360 for azimuth. The observer inputs of in are used as for Spa_compute_time; Function is
+// ignored.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_sun_crossings(start, end time.Time, in Spa_data, kind int, target float64,
+	tolerance time.Duration) ([]Spa_crossing, error) {
+	var spa Spa_data
+	var crossings []Spa_crossing
+
+	switch kind {
+	case SPA_CROSSING_ELEVATION, SPA_CROSSING_HORIZON:
+		if target < -90 || target > 90 {
+			return nil, &Spa_validation_error{Errors: []*Spa_range_error{{Field: "target",
+				Value: target, Min: -90, Max: 90, Range: "-90 to 90 degrees"}}}
+		}
+	case SPA_CROSSING_AZIMUTH:
+		if target < 0 || target > 360 {
+			return nil, &Spa_validation_error{Errors: []*Spa_range_error{{Field: "target",
+				Value: target, Min: 0, Max: 360, Range: "0 to 360 degrees"}}}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown crossing kind %d", ErrInvalidInput, kind)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: window end %v is before its start %v", ErrInvalidInput, end, start)
+	}
+	if tolerance <= 0 {
+		tolerance = SPA_CROSSING_TOLERANCE
+	}
+
+	in.Function = SPA_ZA
+	for _, t := range [2]time.Time{start, end} {
+		spa = in
+		spa_set_time(&spa, t.UTC())
+		if err := Spa_validate(&spa); err != nil {
+			return nil, err
+		}
+	}
+
+	loc := start.Location()
+
+	t0 := start
+	if err := spa_sun_position_at(t0, &in, &spa); err != nil {
+		return nil, err
+	}
+	f0 := spa_crossing_offset(&spa, kind, target)
+
+	for t0.Before(end) {
+		t1 := t0.Add(SPA_CROSSING_STEP)
+		if t1.After(end) {
+			t1 = end
+		}
+		if err := spa_sun_position_at(t1, &in, &spa); err != nil {
+			return nil, err
+		}
+		f1 := spa_crossing_offset(&spa, kind, target)
+
+		if f0 != 0 && math.Signbit(f0) != math.Signbit(f1) &&
+			(kind != SPA_CROSSING_AZIMUTH || math.Abs(f1-f0) < 180) {
+			direction := SPA_CROSSING_RISING
+			if f1 < f0 {
+				direction = SPA_CROSSING_SETTING
+			}
+
+			a, b, fa := t0, t1, f0
+			for b.Sub(a) > tolerance {
+				m := a.Add(b.Sub(a) / 2)
+				if err := spa_sun_position_at(m, &in, &spa); err != nil {
+					return nil, err
+				}
+				fm := spa_crossing_offset(&spa, kind, target)
+				if math.Signbit(fm) == math.Signbit(fa) {
+					a, fa = m, fm
+				} else {
+					b = m
+				}
+			}
+
+			t := a.Add(b.Sub(a) / 2)
+			if err := spa_sun_position_at(t, &in, &spa); err != nil {
+				return nil, err
+			}
+			crossings = append(crossings, Spa_crossing{Time: t.In(loc), Direction: direction,
+				Elevation: spa.e, Azimuth: spa.Azimuth})
+		}
+
+		t0, f0 = t1, f1
+	}
+
+	return crossings, nil
+}
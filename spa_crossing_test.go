@@ -0,0 +1,109 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpa_sun_crossings(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         Spa_data
+		start, end time.Time
+		kind       int
+		target     float64
+		tolerance  time.Duration
+		count      int // crossings expected, -1 to only check them
+	}{
+		// near the pole the sun skims the horizon for days around the equinox
+		{"sunrise at 89.5 N", rts_site(89.5, 0), time.Date(2023, 3, 17, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 3, 24, 0, 0, 0, 0, time.UTC), SPA_CROSSING_ELEVATION, 0, 0, -1},
+		{"sunset at 89.5 N", rts_site(89.5, 0), time.Date(2023, 9, 20, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 9, 27, 0, 0, 0, 0, time.UTC), SPA_CROSSING_ELEVATION, 0, time.Millisecond, -1},
+		{"Longyearbyen midnight sun, south", rts_site(78.2232, 15.6267), time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 24, 0, 0, 0, 0, time.UTC), SPA_CROSSING_AZIMUTH, 180, 0, 3},
+		{"Longyearbyen midnight sun, north", rts_site(78.2232, 15.6267), time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 24, 0, 0, 0, 0, time.UTC), SPA_CROSSING_AZIMUTH, 0, 0, 3},
+		{"Longyearbyen midnight sun, lowest elevation", rts_site(78.2232, 15.6267), time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 24, 0, 0, 0, 0, time.UTC), SPA_CROSSING_ELEVATION, 0, 0, 0},
+		{"Tromso civil dusk and dawn", rts_site(69.6496, 18.9560), time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 12, 22, 0, 0, 0, 0, time.UTC), SPA_CROSSING_ELEVATION, -6, 0, 4},
+	}
+
+	for _, tt := range tests {
+		crossings, err := Spa_sun_crossings(tt.start, tt.end, tt.in, tt.kind, tt.target, tt.tolerance)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.count >= 0 && len(crossings) != tt.count {
+			t.Errorf("%s: %d crossings, want %d", tt.name, len(crossings), tt.count)
+		}
+		if tt.count < 0 && len(crossings) == 0 {
+			t.Errorf("%s: no crossings", tt.name)
+		}
+
+		tolerance := tt.tolerance
+		if tolerance == 0 {
+			tolerance = SPA_CROSSING_TOLERANCE
+		}
+
+		for i, c := range crossings {
+			if c.Time.Before(tt.start) || c.Time.After(tt.end) {
+				t.Errorf("%s: crossing at %v outside the window", tt.name, c.Time)
+			}
+			if i > 0 && tt.kind != SPA_CROSSING_AZIMUTH && c.Direction == crossings[i-1].Direction {
+				t.Errorf("%s: crossings %d and %d are both in direction %d", tt.name, i-1, i, c.Direction)
+			}
+
+			// the target lies between the positions half a tolerance before and after
+			var before, after Spa_data
+			in := tt.in
+			if err := spa_sun_position_at(c.Time.Add(-tolerance/2), &in, &before); err != nil {
+				t.Fatal(err)
+			}
+			if err := spa_sun_position_at(c.Time.Add(tolerance/2), &in, &after); err != nil {
+				t.Fatal(err)
+			}
+			fb := spa_crossing_offset(&before, tt.kind, tt.target)
+			fa := spa_crossing_offset(&after, tt.kind, tt.target)
+			if math.Signbit(fb) == math.Signbit(fa) {
+				t.Errorf("%s: crossing at %v is not within %v of the target (%v, %v)", tt.name, c.Time,
+					tolerance, fb, fa)
+			}
+			if (c.Direction == SPA_CROSSING_RISING) != (fa > fb) {
+				t.Errorf("%s: crossing at %v has direction %d", tt.name, c.Time, c.Direction)
+			}
+
+			got := c.Elevation
+			if tt.kind == SPA_CROSSING_AZIMUTH {
+				got = c.Azimuth
+			}
+			if d := math.Abs(limit_degrees180pm(got - tt.target)); d > 0.01 {
+				t.Errorf("%s: %v at the crossing at %v, want %v", tt.name, got, c.Time, tt.target)
+			}
+		}
+	}
+
+	start := time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC)
+	in := rts_site(40, 0)
+	var validation *Spa_validation_error
+
+	if _, err := Spa_sun_crossings(start, start.Add(-time.Hour), in, SPA_CROSSING_ELEVATION, 0, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("end before start: error %v, want ErrInvalidInput", err)
+	}
+	if _, err := Spa_sun_crossings(start, start.Add(time.Hour), in, 3, 0, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown kind: error %v, want ErrInvalidInput", err)
+	}
+	if _, err := Spa_sun_crossings(start, start.Add(time.Hour), in, SPA_CROSSING_ELEVATION, 91, 0); !errors.As(err, &validation) {
+		t.Errorf("elevation 91: error %v, want a validation error", err)
+	}
+	if _, err := Spa_sun_crossings(start, start.Add(time.Hour), in, SPA_CROSSING_AZIMUTH, 361, 0); !errors.As(err, &validation) {
+		t.Errorf("azimuth 361: error %v, want a validation error", err)
+	}
+	in.Latitude = 91
+	if _, err := Spa_sun_crossings(start, start.Add(time.Hour), in, SPA_CROSSING_ELEVATION, 0, 0); !errors.As(err, &validation) {
+		t.Errorf("latitude 91: error %v, want a validation error", err)
+	}
+}
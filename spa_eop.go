@@ -0,0 +1,91 @@
+package gosolar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Spa_earth_orientation supplies the Earth orientation values SPA needs for an instant (UTC).
+// *iers.Data from the iers subpackage implements it from IERS bulletins and leap second files.
+type Spa_earth_orientation interface {
+	DUT1(t time.Time) (float64, error)    // UT1-UTC [seconds]
+	TAI_UTC(t time.Time) (float64, error) // TAI-UTC [seconds]
+}
+
+// Spa_leap_seconds supplies TAI-UTC for an instant (UTC), e.g. *iers.Leap_table, *iers.Data or
+// astrotime.Builtin_leap_seconds. A leap second ends the minute before TAI-UTC steps up by one.
+type Spa_leap_seconds interface {
+	TAI_UTC(t time.Time) (float64, error) // TAI-UTC [seconds]
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// UTC instant of the date, time and time zone inputs in structure
+// Note: In a leap second (Second 60 to 61) it is the instant of second 59, so that DUT1 and
+// TAI-UTC are those of the day the leap second belongs to
+///////////////////////////////////////////////////////////////////////////////////////////////
+func spa_utc_time(spa *Spa_data) time.Time {
+	second := spa.Second
+	if second >= 60 {
+		second--
+	}
+
+	sec := math.Floor(second)
+	t := time.Date(spa.Year, time.Month(spa.Month), spa.Day, spa.Hour, spa.Minute,
+		int(sec), int(math.Round((second-sec)*1e9)), time.UTC)
+
+	return t.Add(-time.Duration(spa.Timezone * float64(time.Hour)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Fill Delta_ut1 and Delta_t = 32.184 + (TAI-UTC) - DUT1 from Earth_orientation, if set
+///////////////////////////////////////////////////////////////////////////////////////////////
+func apply_earth_orientation(spa *Spa_data) error {
+	if spa.Earth_orientation == nil {
+		return nil
+	}
+
+	t := spa_utc_time(spa)
+
+	dut1, err := spa.Earth_orientation.DUT1(t)
+	if err != nil {
+		return fmt.Errorf("gosolar: Delta_ut1 lookup: %w", err)
+	}
+	tai_utc, err := spa.Earth_orientation.TAI_UTC(t)
+	if err != nil {
+		return fmt.Errorf("gosolar: Delta_t lookup: %w", err)
+	}
+
+	spa.Delta_ut1 = dut1
+	spa.Delta_t = TT_TAI + tai_utc - dut1
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Is Second in structure a leap second: 60 to 61 at the end of a UTC day (in local time) that
+// the leap second table (Leap_seconds, else Earth_orientation) ends with a step of TAI-UTC
+///////////////////////////////////////////////////////////////////////////////////////////////
+func leap_second(spa *Spa_data) bool {
+	var table Spa_leap_seconds = spa.Leap_seconds
+
+	if table == nil && spa.Earth_orientation != nil {
+		table = spa.Earth_orientation
+	}
+	if table == nil || spa.Second >= 61 || spa.Hour > 23 {
+		return false
+	}
+
+	t := spa_utc_time(spa).Truncate(time.Second)
+	if t.Hour() != 23 || t.Minute() != 59 || t.Second() != 59 {
+		return false
+	}
+
+	before, err := table.TAI_UTC(t)
+	if err != nil {
+		return false
+	}
+	after, err := table.TAI_UTC(t.Add(time.Second))
+
+	return err == nil && after-before == 1
+}
@@ -0,0 +1,106 @@
+package gosolar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidInput is matched (with errors.Is) by every input validation error.
+var ErrInvalidInput = errors.New("gosolar: invalid input")
+
+// Spa_range_error reports one Spa_data input field outside its valid range.
+type Spa_range_error struct {
+	Field string  // Spa_data field (or function argument) name, e.g. "Temperature"
+	Value float64 // offending value
+	Min   float64 // lower bound of the valid range
+	Max   float64 // upper bound of the valid range
+	Range string  // valid range as documented on Spa_data, e.g. "-273 (exclusive) to 6000"
+	Code  int     // error code returned by Spa_calculate for this field, 0 for an argument
+}
+
+func (e *Spa_range_error) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("gosolar: %s = %g is out of range, valid range: %s", e.Field, e.Value, e.Range)
+	}
+
+	return fmt.Sprintf("gosolar: %s = %g is out of range, valid range: %s (error code %d)",
+		e.Field, e.Value, e.Range, e.Code)
+}
+
+func (e *Spa_range_error) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// Spa_validation_error holds every invalid input field found in a Spa_data, in the
+// order Spa_calculate checks them. errors.Is and errors.As see each field error.
+type Spa_validation_error struct {
+	Errors []*Spa_range_error
+}
+
+func (e *Spa_validation_error) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = strings.TrimPrefix(err.Error(), "gosolar: ")
+	}
+
+	return fmt.Sprintf("gosolar: %d invalid inputs: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *Spa_validation_error) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+func (e *Spa_validation_error) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Validate all inputs in structure
+// Returns nil, or a *Spa_validation_error listing every invalid field (matches ErrInvalidInput)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_validate(spa *Spa_data) error {
+	if errs := validate_inputs_all(spa); len(errs) > 0 {
+		return &Spa_validation_error{Errors: errs}
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters and put into structure, reporting invalid inputs as an error
+// Note: Same as Spa_calculate, but returns the result of Spa_validate instead of a code
+///////////////////////////////////////////////////////////////////////////////////////////
+func Spa_calculate_checked(spa *Spa_data) error {
+	if err := Spa_validate(spa); err != nil {
+		return err
+	}
+	if err := apply_earth_orientation(spa); err != nil {
+		return err
+	}
+
+	calculate_all(spa)
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters for the instant t, reporting invalid inputs as an error
+// Note: A leap second folded into the next minute by time.Time is calculated one second late
+// (see Spa_calculate_time)
+///////////////////////////////////////////////////////////////////////////////////////////
+func Spa_calculate_time_checked(t time.Time, spa *Spa_data) error {
+	spa_set_time(spa, t)
+
+	return Spa_calculate_checked(spa)
+}
@@ -0,0 +1,244 @@
+package gosolar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spa_horizon is a horizon profile: the elevation of the terrain and buildings seen from the
+// observer as a function of azimuth, linearly interpolated and wrapping around north.
+type Spa_horizon struct {
+	Azimuth   []float64 // azimuth (eastward from north), sorted ascending, 0 to <360 [degrees]
+	Elevation []float64 // elevation angle of the horizon line at Azimuth [degrees]
+}
+
+// Spa_sun_period is an interval of unobstructed sun.
+type Spa_sun_period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Spa_horizon_day holds the sun visibility over a horizon profile for one local calendar day.
+type Spa_horizon_day struct {
+	Sunrise   time.Time        // first time the sun center rises above the profile, zero if it does not
+	Sunset    time.Time        // last time the sun center sets behind the profile, zero if it does not
+	Periods   []Spa_sun_period // every interval of unobstructed sun within the day, in order
+	Sun_hours float64          // total length of Periods [hours]
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Elevation angle of the horizon line at azimuth (eastward from north) [degrees]
+// Note: An empty profile is the flat horizon (elevation 0)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (h *Spa_horizon) Elevation_at(azimuth float64) float64 {
+	n := len(h.Azimuth)
+	if n == 0 {
+		return 0
+	}
+	if n == 1 {
+		return h.Elevation[0]
+	}
+
+	azimuth = limit_degrees(azimuth)
+
+	i := sort.SearchFloat64s(h.Azimuth, azimuth)
+	if i < n && h.Azimuth[i] == azimuth {
+		return h.Elevation[i]
+	}
+
+	// between the last and the first point the profile wraps through north
+	a0, e0, a1, e1 := h.Azimuth[n-1]-360.0, h.Elevation[n-1], h.Azimuth[0], h.Elevation[0]
+	if i > 0 && i < n {
+		a0, e0, a1, e1 = h.Azimuth[i-1], h.Elevation[i-1], h.Azimuth[i], h.Elevation[i]
+	} else if i == n {
+		a0, e0, a1, e1 = h.Azimuth[n-1], h.Elevation[n-1], h.Azimuth[0]+360.0, h.Elevation[0]
+	}
+
+	return e0 + (e1-e0)*(azimuth-a0)/(a1-a0)
+}
+
+func (h *Spa_horizon) add(azimuth, elevation float64) {
+	azimuth = limit_degrees(azimuth)
+	i := sort.SearchFloat64s(h.Azimuth, azimuth)
+
+	if i < len(h.Azimuth) && h.Azimuth[i] == azimuth {
+		h.Elevation[i] = elevation
+		return
+	}
+
+	h.Azimuth = append(h.Azimuth, 0)
+	h.Elevation = append(h.Elevation, 0)
+	copy(h.Azimuth[i+1:], h.Azimuth[i:])
+	copy(h.Elevation[i+1:], h.Elevation[i:])
+	h.Azimuth[i], h.Elevation[i] = azimuth, elevation
+}
+
+// numeric fields of a line split at commas, semicolons or white space (nil if any is not a number)
+func horizon_fields(line string) []float64 {
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+
+	values := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil
+		}
+		values[i] = v
+	}
+
+	return values
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse a horizon profile from CSV lines "azimuth,elevation" [degrees]
+//
+// Azimuth is eastward from north. Fields may also be separated by semicolons or white space;
+// empty lines, comment lines (starting with '#') and non-numeric header lines are skipped.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_horizon_csv(r io.Reader) (*Spa_horizon, error) {
+	h := &Spa_horizon{}
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line_no++
+
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		values := horizon_fields(line)
+		if values == nil {
+			if len(h.Azimuth) == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("gosolar: horizon CSV line %d: expected azimuth,elevation", line_no)
+		}
+		if len(values) < 2 {
+			return nil, fmt.Errorf("gosolar: horizon CSV line %d: expected azimuth,elevation", line_no)
+		}
+
+		h.add(values[0], values[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(h.Azimuth) == 0 {
+		return nil, fmt.Errorf("gosolar: no horizon points found in CSV data")
+	}
+
+	return h, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse a PVGIS horizon profile
+//
+// Two forms are accepted: the table of the PVGIS horizon tool (columns A and H_hor, with A
+// measured from south, 0 = S, 90 = W, -90 = E), and the PVGIS user horizon file, a single
+// column of elevations equally spaced in azimuth clockwise starting at north.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_horizon_pvgis(r io.Reader) (*Spa_horizon, error) {
+	h := &Spa_horizon{}
+	var heights []float64
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line_no++
+
+		values := horizon_fields(line)
+		switch {
+		case len(values) == 1:
+			heights = append(heights, values[0])
+		case len(values) >= 2:
+			h.add(values[0]+180.0, values[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(h.Azimuth) > 0 && len(heights) > 0 {
+		return nil, fmt.Errorf("gosolar: PVGIS horizon data mixes table rows and single values")
+	}
+	for i, e := range heights {
+		h.add(360.0*float64(i)/float64(len(heights)), e)
+	}
+	if len(h.Azimuth) == 0 {
+		return nil, fmt.Errorf("gosolar: no horizon points found in PVGIS data")
+	}
+
+	return h, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Elevation angle of the horizon in structure at azimuth (flat, 0, without a profile)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func horizon_elevation(spa *Spa_data, azimuth float64) float64 {
+	if spa.Horizon == nil {
+		return 0
+	}
+
+	return spa.Horizon.Elevation_at(azimuth)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the sun visibility over the horizon profile on the local calendar day of t
+//
+// The sun counts as visible while its refraction corrected center is above the profile
+// (Horizon of in, flat when nil). The crossings are found with the SPA_CROSSING_HORIZON
+// solver, so obstructions narrower than the sun moves in SPA_CROSSING_STEP may be missed.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_sun_horizon_day(t time.Time, in Spa_data) (Spa_horizon_day, error) {
+	var day Spa_horizon_day
+	var spa Spa_data
+
+	loc := t.Location()
+	year, month, mday := t.Date()
+	day_start := time.Date(year, month, mday, 0, 0, 0, 0, loc)
+	day_end := day_start.AddDate(0, 0, 1)
+
+	crossings, err := Spa_sun_crossings(day_start, day_end, in, SPA_CROSSING_HORIZON, 0, 0)
+	if err != nil {
+		return day, err
+	}
+
+	in.Function = SPA_ZA
+	if err := spa_sun_position_at(day_start, &in, &spa); err != nil {
+		return day, err
+	}
+
+	start, visible := day_start, !spa.Obstructed
+	for _, c := range crossings {
+		if c.Direction == SPA_CROSSING_RISING {
+			if day.Sunrise.IsZero() {
+				day.Sunrise = c.Time
+			}
+			start, visible = c.Time, true
+		} else {
+			day.Sunset = c.Time
+			if visible {
+				day.Periods = append(day.Periods, Spa_sun_period{Start: start, End: c.Time})
+			}
+			visible = false
+		}
+	}
+	if visible {
+		day.Periods = append(day.Periods, Spa_sun_period{Start: start, End: day_end})
+	}
+
+	for _, p := range day.Periods {
+		day.Sun_hours += p.End.Sub(p.Start).Hours()
+	}
+
+	return day, nil
+}
@@ -0,0 +1,236 @@
+package gosolar
+
+import (
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func open_horizon(t *testing.T, name string, parse func(r io.Reader) (*Spa_horizon, error)) *Spa_horizon {
+	t.Helper()
+
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	h, err := parse(f)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	return h
+}
+
+func TestHorizon_dip(t *testing.T) {
+	// the dip of the sea horizon is 1.76' sqrt(h), terrestrial refraction included (Nautical
+	// Almanac): 17.6' from 100 m, 55.7' from 1000 m
+	for _, tt := range []struct {
+		height, dip float64 // [meters], [arcminutes]
+	}{
+		{0, 0},
+		{1, 1.76},
+		{100, 17.6},
+		{1000, 55.66},
+	} {
+		spa := example_spa_data()
+		spa.Observer_height = tt.height
+		if got := 60 * horizon_dip(&spa); math.Abs(got-tt.dip) > 0.1 {
+			t.Errorf("height %v m: dip %.2f', want %.2f'", tt.height, got, tt.dip)
+		}
+		if got, want := sun_rise_set_altitude(&spa), -(SUN_RADIUS + horizon_dip(&spa) + spa.Atmos_refract); got != want {
+			t.Errorf("height %v m: rise/set altitude %v, want %v", tt.height, got, want)
+		}
+	}
+
+	// the sun rises earlier and sets later over a dipped horizon
+	ground, tower := example_spa_data(), example_spa_data()
+	tower.Observer_height = 100
+	Spa_calculate(&ground)
+	Spa_calculate(&tower)
+	if tower.Sunrise >= ground.Sunrise || tower.Sunset <= ground.Sunset {
+		t.Errorf("from 100 m: sunrise %v, sunset %v; at the ground %v, %v", tower.Sunrise, tower.Sunset,
+			ground.Sunrise, ground.Sunset)
+	}
+	if tower.Zenith != ground.Zenith {
+		t.Errorf("Observer_height changes the zenith angle: %v, %v", tower.Zenith, ground.Zenith)
+	}
+
+	spa := example_spa_data()
+	spa.Observer_height = -1
+	if code := Spa_calculate(&spa); code != 19 {
+		t.Errorf("negative Observer_height: error code %d, want 19", code)
+	}
+}
+
+func TestHorizon_parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		h         *Spa_horizon
+		azimuth   []float64
+		elevation []float64
+	}{
+		{"horizon.csv", open_horizon(t, "horizon.csv", Parse_horizon_csv),
+			[]float64{0, 45, 90, 135, 180, 225, 270, 315, 350},
+			[]float64{8.5, 12, 20.5, 6, 2, 4.5, 15, 10, 9}},
+		// A is measured from south, 0 = S, 90 = W, -90 = E
+		{"horizon_pvgis.txt", open_horizon(t, "horizon_pvgis.txt", Parse_horizon_pvgis),
+			[]float64{0, 45, 90, 135, 180, 225, 270, 315},
+			[]float64{4.6, 3.1, 12.2, 7.6, 2.3, 5.0, 9.9, 6.4}},
+		// equally spaced from north, clockwise
+		{"horizon_pvgis_user.txt", open_horizon(t, "horizon_pvgis_user.txt", Parse_horizon_pvgis),
+			[]float64{0, 45, 90, 135, 180, 225, 270, 315},
+			[]float64{4.6, 3.6, 12.2, 9.9, 2.3, 5.0, 9.9, 3.1}},
+	}
+
+	for _, tt := range tests {
+		if len(tt.h.Azimuth) != len(tt.azimuth) || len(tt.h.Elevation) != len(tt.elevation) {
+			t.Errorf("%s: %v %v, want %v %v", tt.name, tt.h.Azimuth, tt.h.Elevation, tt.azimuth, tt.elevation)
+			continue
+		}
+		for i := range tt.azimuth {
+			if tt.h.Azimuth[i] != tt.azimuth[i] || tt.h.Elevation[i] != tt.elevation[i] {
+				t.Errorf("%s: point %d is %v/%v, want %v/%v", tt.name, i, tt.h.Azimuth[i], tt.h.Elevation[i],
+					tt.azimuth[i], tt.elevation[i])
+			}
+		}
+	}
+
+	// unsorted input, white space and semicolons, a repeated azimuth replaces the point
+	h, err := Parse_horizon_csv(strings.NewReader("270 15\n90;20\n\n-90,21\n450,30\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Azimuth) != 2 || h.Elevation[0] != 30 || h.Elevation[1] != 21 {
+		t.Errorf("points %v %v, want 90/30 and 270/21", h.Azimuth, h.Elevation)
+	}
+
+	for _, bad := range []struct {
+		name  string
+		data  string
+		pvgis bool
+	}{
+		{"empty CSV", "", false},
+		{"only a header", "azimuth,elevation\n", false},
+		{"one field", "90,10\n180\n", false},
+		{"text after the points", "90,10\nnorth,5\n", false},
+		{"empty PVGIS", "PVGIS\n", true},
+		{"PVGIS mixing rows and values", "0.0\t2.3\n5.0\n", true},
+	} {
+		var err error
+		if bad.pvgis {
+			_, err = Parse_horizon_pvgis(strings.NewReader(bad.data))
+		} else {
+			_, err = Parse_horizon_csv(strings.NewReader(bad.data))
+		}
+		if err == nil {
+			t.Errorf("%s: no error", bad.name)
+		}
+	}
+}
+
+func TestHorizon_elevation_at(t *testing.T) {
+	h := &Spa_horizon{Azimuth: []float64{10, 90, 350}, Elevation: []float64{20, 40, 10}}
+
+	// linear between the points, and through north between the last and the first
+	for _, tt := range []struct {
+		azimuth, elevation float64
+	}{
+		{10, 20},
+		{50, 30},
+		{90, 40},
+		{220, 25},
+		{350, 10},
+		{355, 12.5},
+		{0, 15},
+		{360, 15},
+		{5, 17.5},
+		{-5, 12.5},
+		{725, 17.5},
+	} {
+		if got := h.Elevation_at(tt.azimuth); math.Abs(got-tt.elevation) > 1e-12 {
+			t.Errorf("azimuth %v: elevation %v, want %v", tt.azimuth, got, tt.elevation)
+		}
+	}
+
+	if got := (&Spa_horizon{}).Elevation_at(123); got != 0 {
+		t.Errorf("empty profile: elevation %v, want 0", got)
+	}
+	if got := (&Spa_horizon{Azimuth: []float64{90}, Elevation: []float64{7}}).Elevation_at(270); got != 7 {
+		t.Errorf("one point: elevation %v, want 7", got)
+	}
+}
+
+func TestHorizon_obstructed(t *testing.T) {
+	// the report example sun stands 39.89 degrees high, south by west (azimuth 194.34)
+	for _, tt := range []struct {
+		name       string
+		horizon    *Spa_horizon
+		obstructed bool
+	}{
+		{"flat", nil, false},
+		{"wall of 35 degrees", &Spa_horizon{Azimuth: []float64{0}, Elevation: []float64{35}}, false},
+		{"wall of 45 degrees", &Spa_horizon{Azimuth: []float64{0}, Elevation: []float64{45}}, true},
+		{"mountain in the south", &Spa_horizon{Azimuth: []float64{90, 190, 200, 270}, Elevation: []float64{0, 60, 60, 0}}, true},
+		{"mountain in the north", &Spa_horizon{Azimuth: []float64{0, 90, 270}, Elevation: []float64{60, 0, 0}}, false},
+	} {
+		spa := example_spa_data()
+		spa.Horizon = tt.horizon
+		if code := Spa_calculate(&spa); code != 0 {
+			t.Fatalf("%s: error code %d", tt.name, code)
+		}
+		if spa.Obstructed != tt.obstructed {
+			t.Errorf("%s: Obstructed %v, want %v", tt.name, spa.Obstructed, tt.obstructed)
+		}
+	}
+
+	// a profile only shortens the sun hours of the day
+	mst := time.FixedZone("MST", -7*3600)
+	day := time.Date(2003, 10, 17, 12, 0, 0, 0, mst)
+	in := example_spa_data()
+
+	flat, err := Spa_sun_horizon_day(day, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rts, err := Spa_sun_rts(day, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flat.Periods) != 1 || math.Abs(flat.Sun_hours-rts.Sunset.Sub(rts.Sunrise).Hours()) > 0.1 {
+		t.Errorf("flat horizon: %d periods, %v sun hours; sunrise to sunset is %v", len(flat.Periods),
+			flat.Sun_hours, rts.Sunset.Sub(rts.Sunrise))
+	}
+
+	in.Horizon = open_horizon(t, "horizon.csv", Parse_horizon_csv)
+	valley, err := Spa_sun_horizon_day(day, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valley.Sunrise.After(flat.Sunrise) || !valley.Sunset.Before(flat.Sunset) || valley.Sun_hours >= flat.Sun_hours {
+		t.Errorf("valley: sun from %v to %v, %v hours; flat from %v to %v, %v hours", valley.Sunrise, valley.Sunset,
+			valley.Sun_hours, flat.Sunrise, flat.Sunset, flat.Sun_hours)
+	}
+	for _, e := range []time.Time{valley.Sunrise, valley.Sunset} {
+		var spa Spa_data
+		if err := spa_sun_position_at(e, &in, &spa); err != nil {
+			t.Fatal(err)
+		}
+		if d := spa.e - in.Horizon.Elevation_at(spa.Azimuth); math.Abs(d) > 0.01 {
+			t.Errorf("valley: sun %v above the profile at %v", d, e)
+		}
+	}
+
+	in.Horizon = &Spa_horizon{Azimuth: []float64{0}, Elevation: []float64{80}}
+	walled, err := Spa_sun_horizon_day(day, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(walled.Periods) != 0 || walled.Sun_hours != 0 || !walled.Sunrise.IsZero() {
+		t.Errorf("walled in: %v", walled)
+	}
+}
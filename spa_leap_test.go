@@ -0,0 +1,131 @@
+package gosolar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// leap second at the end of 2016: TAI-UTC 36 to 37 seconds, DUT1 -0.4 to +0.6 seconds
+type leap_2016 struct{}
+
+var leap_2016_end = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (leap_2016) TAI_UTC(t time.Time) (float64, error) {
+	if t.Before(leap_2016_end) {
+		return 36, nil
+	}
+	return 37, nil
+}
+
+func (leap_2016) DUT1(t time.Time) (float64, error) {
+	if t.Before(leap_2016_end) {
+		return -0.4, nil
+	}
+	return 0.6, nil
+}
+
+func leap_spa_data(hour, minute int, second, timezone float64) Spa_data {
+	spa := example_spa_data()
+	spa.Year, spa.Month, spa.Day = 2016, 12, 31
+	spa.Hour, spa.Minute, spa.Second, spa.Timezone = hour, minute, second, timezone
+	spa.Function = SPA_ZA
+
+	return spa
+}
+
+func TestLeap_second_validate(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		spa    Spa_data
+		table  Spa_leap_seconds
+		orient Spa_earth_orientation
+		want   int
+	}{
+		{"no table", leap_spa_data(23, 59, 60.5, 0), nil, nil, 6},
+		{"leap second", leap_spa_data(23, 59, 60.5, 0), leap_2016{}, nil, 0},
+		{"from Earth_orientation", leap_spa_data(23, 59, 60.5, 0), nil, leap_2016{}, 0},
+		{"local time", leap_spa_data(18, 59, 60, -5), leap_2016{}, nil, 0},
+		{"not at the end of the UTC day", leap_spa_data(23, 59, 60, -5), leap_2016{}, nil, 6},
+		{"second 61", leap_spa_data(23, 59, 61, 0), leap_2016{}, nil, 6},
+	} {
+		tt.spa.Leap_seconds, tt.spa.Earth_orientation = tt.table, tt.orient
+		if code := Spa_calculate(&tt.spa); code != tt.want {
+			t.Errorf("%s: error code %d, want %d", tt.name, code, tt.want)
+		}
+	}
+
+	spa := leap_spa_data(23, 59, 60.5, 0)
+	spa.Year = 2015
+	spa.Leap_seconds = leap_2016{}
+	if code := Spa_calculate(&spa); code != 6 {
+		t.Errorf("second 60 without a leap second: error code %d, want 6", code)
+	}
+}
+
+func TestLeap_second_julian_day(t *testing.T) {
+	// UT1 and TT advance one second per second of UTC through the leap second
+	var prev Spa_result
+	for i, second := range []float64{59.5, 60.5, 0.5} {
+		in := leap_spa_data(23, 59, second, 0)
+		if i == 2 {
+			in = example_spa_data()
+			in.Year, in.Month, in.Day, in.Hour, in.Minute, in.Second, in.Timezone = 2017, 1, 1, 0, 0, second, 0
+		}
+		in.Earth_orientation = leap_2016{}
+
+		r, err := Spa_compute(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 {
+			if d := (r.Jd - prev.Jd) * 86400; math.Abs(d-1) > 1e-4 {
+				t.Errorf("second %v: UT1 advanced %.6f seconds, want 1", second, d)
+			}
+			if d := (r.Jde - prev.Jde) * 86400; math.Abs(d-1) > 1e-4 {
+				t.Errorf("second %v: TT advanced %.6f seconds, want 1", second, d)
+			}
+		}
+		prev = r
+	}
+}
+
+func TestLeap_second_time(t *testing.T) {
+	in := leap_spa_data(0, 0, 0, 0)
+	in.Leap_seconds = leap_2016{}
+
+	fields := leap_spa_data(23, 59, 60.5, 0)
+	fields.Leap_seconds = leap_2016{}
+	want, err := Spa_compute(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Spa_compute_time_leap(time.Date(2016, 12, 31, 23, 59, 60, 5e8, time.UTC), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Jd != want.Jd || got.Zenith != want.Zenith {
+		t.Errorf("Spa_compute_time_leap: Jd %.8f, zenith %.6f; want %.8f, %.6f", got.Jd, got.Zenith, want.Jd, want.Zenith)
+	}
+
+	// the time.Time path folds the leap second into the next minute, one second late
+	in.Earth_orientation = leap_2016{}
+	leap, err := Spa_compute_time_leap(time.Date(2016, 12, 31, 23, 59, 60, 5e8, time.UTC), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folded, err := Spa_compute_time(time.Date(2016, 12, 31, 23, 59, 60, 5e8, time.UTC), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := (folded.Jd - leap.Jd) * 86400; math.Abs(d-1) > 1e-4 {
+		t.Errorf("Spa_compute_time: UT1 %.6f seconds after the leap second, want 1", d)
+	}
+	in.Earth_orientation = nil
+
+	local := time.FixedZone("EST", -5*3600)
+	if code := Spa_calculate_time_leap(time.Date(2016, 12, 31, 19, 0, 0, 0, local), &in); code != 0 || in.Second != 60 {
+		t.Errorf("Spa_calculate_time_leap in EST: error code %d, Second %v", code, in.Second)
+	}
+}
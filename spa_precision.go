@@ -0,0 +1,41 @@
+package gosolar
+
+const (
+	SPA_PRECISION_FULL   = iota //all terms of the NREL SPA report (+/-0.0003 degrees)
+	SPA_PRECISION_HIGH          //sun position within 0.001 degrees of FULL, SPA_ZA about 2.5 times faster
+	SPA_PRECISION_MEDIUM        //sun position within 0.003 degrees of FULL, SPA_ZA about 3.5 times faster
+	SPA_PRECISION_LOW           //sun position within 0.01 degrees of FULL, SPA_ZA about 5 times faster
+	SPA_PRECISION_COUNT
+)
+
+// accuracy budget of each precision level relative to SPA_PRECISION_FULL [degrees]
+var precision_budget = [SPA_PRECISION_COUNT]float64{0, 0.001, 0.003, 0.01}
+
+// number of terms summed at a precision level: the earth periodic terms of each power of jme
+// and the nutation terms (the tables are ordered by decreasing amplitude, so the first terms
+// are kept)
+type spa_precision_terms struct {
+	l [L_COUNT]int
+	b [B_COUNT]int
+	r [R_COUNT]int
+	y int
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Terms of each precision level
+//
+// Each level keeps the terms whose dropping would exceed its budget at the ends of the year
+// range (jme = +/-4), where the higher powers of jme weigh most. The budget bounds the angular
+// distance between the sun of the level and of SPA_PRECISION_FULL, both the geocentric apparent
+// right ascension and declination and the topocentric zenith and azimuth, over the years -2000
+// to 6000 (TestPrecision_levels). The geocentric sun position itself gets about 2.5, 4 and 6.5
+// times faster (BenchmarkSpa_geocentric_precision); the topocentric part of Spa_calculate is
+// not truncated, so SPA_ZA as a whole gets 2.5, 3.5 and 5 times faster
+// (BenchmarkSpa_calculate_precision).
+///////////////////////////////////////////////////////////////////////////////////////////////
+var precision_terms = [SPA_PRECISION_COUNT]spa_precision_terms{
+	{l: l_subcount, b: b_subcount, r: r_subcount, y: Y_COUNT},
+	{l: [L_COUNT]int{40, 14, 8, 3, 2, 1}, b: [B_COUNT]int{2, 1}, r: [R_COUNT]int{6, 2, 1, 0, 0}, y: 10},
+	{l: [L_COUNT]int{26, 8, 3, 2, 1, 1}, b: [B_COUNT]int{0, 0}, r: [R_COUNT]int{2, 1, 0, 0, 0}, y: 4},
+	{l: [L_COUNT]int{10, 3, 2, 1, 1, 0}, b: [B_COUNT]int{0, 0}, r: [R_COUNT]int{2, 0, 0, 0, 0}, y: 2},
+}
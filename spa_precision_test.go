@@ -0,0 +1,114 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPrecision_levels(t *testing.T) {
+	const samples = 40000
+	const jd_first, jd_last = 990557.5, 3912910.0 // -2000 January 1 to 6000 December 31
+
+	for level := SPA_PRECISION_HIGH; level < SPA_PRECISION_COUNT; level++ {
+		var max_geocentric, max_topocentric float64
+
+		for i := 0; i < samples; i++ {
+			full := Spa_data{
+				Jd:            jd_first + float64(i)*(jd_last-jd_first)/samples + float64(i%7)*0.37,
+				Latitude:      float64(i%140) - 70,
+				Longitude:     float64((i*37)%360) - 180,
+				Pressure:      1010,
+				Temperature:   10,
+				Atmos_refract: 0.5667,
+			}
+			truncated := full
+			truncated.Precision = level
+
+			calculate_geocentric_sun_right_ascension_and_declination(&full)
+			calculate_topocentric_sun_position(&full)
+			calculate_geocentric_sun_right_ascension_and_declination(&truncated)
+			calculate_topocentric_sun_position(&truncated)
+
+			max_geocentric = math.Max(max_geocentric,
+				angular_separation(full.alpha, full.delta, truncated.alpha, truncated.delta))
+			max_topocentric = math.Max(max_topocentric,
+				angular_separation(full.Azimuth, full.e0, truncated.Azimuth, truncated.e0))
+		}
+
+		t.Logf("precision level %d: max error %.6f degrees geocentric, %.6f degrees topocentric (budget %g)",
+			level, max_geocentric, max_topocentric, precision_budget[level])
+		if max_geocentric > precision_budget[level] || max_topocentric > precision_budget[level] {
+			t.Errorf("precision level %d exceeds its budget of %g degrees: %.6f geocentric, %.6f topocentric",
+				level, precision_budget[level], max_geocentric, max_topocentric)
+		}
+	}
+}
+
+func TestPrecision_full_unchanged(t *testing.T) {
+	want := example_spa_data()
+	Spa_calculate(&want)
+
+	got := example_spa_data()
+	got.Precision = SPA_PRECISION_FULL
+	Spa_calculate(&got)
+
+	if got.Zenith != want.Zenith || got.Azimuth != want.Azimuth || got.Sunrise != want.Sunrise {
+		t.Errorf("SPA_PRECISION_FULL differs from the default: zenith %v/%v, azimuth %v/%v",
+			got.Zenith, want.Zenith, got.Azimuth, want.Azimuth)
+	}
+}
+
+func TestPrecision_validate(t *testing.T) {
+	for _, level := range []int{-1, SPA_PRECISION_COUNT} {
+		spa := example_spa_data()
+		spa.Precision = level
+
+		if code := Spa_calculate(&spa); code != 20 {
+			t.Errorf("Precision %d: error code %d, want 20", level, code)
+		}
+		if _, err := Spa_compute(spa); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Precision %d: Spa_compute error %v, want ErrInvalidInput", level, err)
+		}
+	}
+}
+
+var precision_benchmarks = []struct {
+	name      string
+	precision int
+}{
+	{"FULL", SPA_PRECISION_FULL},
+	{"HIGH", SPA_PRECISION_HIGH},
+	{"MEDIUM", SPA_PRECISION_MEDIUM},
+	{"LOW", SPA_PRECISION_LOW},
+}
+
+// the whole SPA_ZA calculation, of which the topocentric part is not truncated
+func BenchmarkSpa_calculate_precision(b *testing.B) {
+	for _, bm := range precision_benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			spa := example_spa_data()
+			spa.Function = SPA_ZA
+			spa.Precision = bm.precision
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Spa_calculate(&spa)
+			}
+		})
+	}
+}
+
+// the geocentric sun position alone, the part the precision levels truncate
+func BenchmarkSpa_geocentric_precision(b *testing.B) {
+	for _, bm := range precision_benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			spa := example_spa_data()
+			spa.Precision = bm.precision
+			calculate_julian_day(&spa)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				calculate_geocentric_sun_right_ascension_and_declination(&spa)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package gosolar
+
+import (
+	"math"
+)
+
+const (
+	RAYTRACE_EARTH_RADIUS  = 6378120.0 //earth radius used by the atmosphere model [meters]
+	RAYTRACE_TROPOPAUSE    = 11000.0   //height of the tropopause [meters]
+	RAYTRACE_STRATOSPHERE  = 80000.0   //upper limit of the integration [meters]
+	RAYTRACE_GAS_CONSTANT  = 8314.32   //universal gas constant [J/(kmol K)]
+	RAYTRACE_DRY_AIR       = 28.9644   //molecular weight of dry air [kg/kmol]
+	RAYTRACE_WATER_VAPOUR  = 18.0152   //molecular weight of water vapour [kg/kmol]
+	RAYTRACE_VAPOUR_EXP    = 18.36     //exponent of the temperature dependence of water vapour pressure
+	RAYTRACE_MAX_ZENITH    = 93.0      //largest observed zenith angle handled (apparent elevation -3) [degrees]
+	RAYTRACE_MAX_INTERVALS = 16384     //most Simpson intervals per atmosphere layer
+	RAYTRACE_LAPSE_RATE    = 0.0065    //default tropospheric lapse rate [K/meter]
+	RAYTRACE_WAVELENGTH    = 0.574     //default effective wavelength (visual sun) [micrometers]
+	RAYTRACE_TOLERANCE     = 1e-8      //default integration tolerance [radians]
+)
+
+// Spa_refraction_raytrace integrates the refraction integral through a model atmosphere: a
+// troposphere with constant lapse rate and relative humidity from the observer up to 11 km,
+// and an isothermal stratosphere above it (Hohenkerk & Sinclair, as in the Explanatory
+// Supplement to the Astronomical Almanac, 1992, 3.281). The observer conditions are Pressure,
+// Temperature and Elevation of Spa_data. It handles apparent elevations down to -3 degrees,
+// so it also applies below a dipped sea horizon, and is much slower than the formula models.
+type Spa_refraction_raytrace struct {
+	Lapse_rate float64 // tropospheric temperature lapse rate [K/meter], 0.001 to 0.01 (0: 0.0065)
+	Humidity   float64 // relative humidity at the observer, 0 to 1
+	Wavelength float64 // effective wavelength [micrometers] (0: 0.574; above 100: radio)
+	Tolerance  float64 // integration tolerance [radians] (0: 1e-8)
+}
+
+// refractive index and r dn/dr in the troposphere at radius r
+func raytrace_troposphere(r0, t0, alpha, gamm2, delm2, c1, c2, c3, c4, c5, c6, r float64,
+	t, dn, rdndr *float64) {
+	*t = math.Max(math.Min(t0-alpha*(r-r0), 320.0), 100.0)
+	tt0 := *t / t0
+	tt0gm2 := math.Pow(tt0, gamm2)
+	tt0dm2 := math.Pow(tt0, delm2)
+	*dn = 1.0 + (c1*tt0gm2-(c2-c5/(*t))*tt0dm2)*tt0
+	*rdndr = r * (-c3*tt0gm2 + (c4-c6/tt0)*tt0dm2)
+}
+
+// refractive index and r dn/dr in the stratosphere at radius r
+func raytrace_stratosphere(rt, tt, dnt, gamal, r float64, dn, rdndr *float64) {
+	b := gamal / tt
+	w := (dnt - 1.0) * math.Exp(-b*(r-rt))
+	*dn = 1.0 + w
+	*rdndr = -r * b * w
+}
+
+// integrand of the refraction integral
+func raytrace_integrand(dn, rdndr float64) float64 {
+	return rdndr / (dn + rdndr)
+}
+
+// zenith angle of the ray at radius r, from the refraction invariant n r sin(z)
+func raytrace_zenith(sk0, r, dn float64) float64 {
+	sine := sk0 / (r * dn)
+	return math.Atan2(sine, math.Sqrt(math.Max(1.0-sine*sine, 0.0)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Refraction [degrees] of a ray seen at the observed zenith angle zobs [degrees]
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (m Spa_refraction_raytrace) refraction(atm Spa_atmosphere, zobs float64) float64 {
+	var tg, dn, rdndr, tt, dnt, rdndrt, dnts, rdndrp, dns, rdndrs float64
+	var reft, refp float64
+
+	z_obs := deg2rad(math.Min(math.Abs(zobs), RAYTRACE_MAX_ZENITH))
+	hm := math.Min(math.Max(atm.Elevation, -1000.0), RAYTRACE_STRATOSPHERE)
+	tdk := math.Min(math.Max(atm.Temperature+273.15, 100.0), 500.0)
+	pmb := math.Min(math.Max(atm.Pressure, 0.0), 10000.0)
+	rh := math.Min(math.Max(m.Humidity, 0.0), 1.0)
+
+	wl := m.Wavelength
+	if wl == 0 {
+		wl = RAYTRACE_WAVELENGTH
+	}
+	wl = math.Max(wl, 0.1)
+	alpha := m.Lapse_rate
+	if alpha == 0 {
+		alpha = RAYTRACE_LAPSE_RATE
+	}
+	alpha = math.Min(math.Max(math.Abs(alpha), 0.001), 0.01)
+	tol := m.Tolerance
+	if tol == 0 {
+		tol = RAYTRACE_TOLERANCE
+	}
+	tol = math.Min(math.Max(math.Abs(tol), 1e-12), 0.1) / 2.0
+
+	optic := wl <= 100.0
+	wlsq := wl * wl
+	gb := 9.784 * (1.0 - 0.0026*math.Cos(2.0*deg2rad(atm.Latitude)) - 0.00000028*hm)
+
+	var a float64
+	if optic {
+		a = (287.6155 + (1.62887+0.01360/wlsq)/wlsq) * 273.15e-6 / 1013.25
+	} else {
+		a = 77.6890e-6
+	}
+
+	gamal := gb * RAYTRACE_DRY_AIR / RAYTRACE_GAS_CONSTANT
+	gamma := gamal / alpha
+	gamm2 := gamma - 2.0
+	delm2 := RAYTRACE_VAPOUR_EXP - 2.0
+
+	tdc := tdk - 273.15
+	psat := math.Pow(10.0, (0.7859+0.03477*tdc)/(1.0+0.00412*tdc)) * (1.0 + pmb*(4.5e-6+6e-10*tdc*tdc))
+	pwo := 0.0
+	if pmb > 0.0 {
+		pwo = rh * psat / (1.0 - (1.0-rh)*psat/pmb)
+	}
+
+	w := pwo * (1.0 - RAYTRACE_WATER_VAPOUR/RAYTRACE_DRY_AIR) * gamma / (RAYTRACE_VAPOUR_EXP - gamma)
+	c1 := a * (pmb + w) / tdk
+	var c2, c5, c6 float64
+	if optic {
+		c2 = (a*w + 11.2684e-6*pwo) / tdk
+	} else {
+		c2 = (a*w + 6.3938e-6*pwo) / tdk
+		c5 = 375463e-6 * pwo / tdk
+		c6 = c5 * delm2 * alpha / (tdk * tdk)
+	}
+	c3 := (gamma - 1.0) * alpha * c1 / tdk
+	c4 := (RAYTRACE_VAPOUR_EXP - 1.0) * alpha * c2 / tdk
+
+	// at the observer
+	r0 := RAYTRACE_EARTH_RADIUS + hm
+	raytrace_troposphere(r0, tdk, alpha, gamm2, delm2, c1, c2, c3, c4, c5, c6, r0, &tg, &dn, &rdndr)
+	sk0 := dn * r0 * math.Sin(z_obs)
+	f0 := raytrace_integrand(dn, rdndr)
+
+	// at the tropopause, troposphere side
+	rt := RAYTRACE_EARTH_RADIUS + math.Max(RAYTRACE_TROPOPAUSE, hm)
+	raytrace_troposphere(r0, tdk, alpha, gamm2, delm2, c1, c2, c3, c4, c5, c6, rt, &tt, &dnt, &rdndrt)
+	zt := raytrace_zenith(sk0, rt, dnt)
+	ft := raytrace_integrand(dnt, rdndrt)
+
+	// at the tropopause, stratosphere side
+	raytrace_stratosphere(rt, tt, dnt, gamal, rt, &dnts, &rdndrp)
+	zts := raytrace_zenith(sk0, rt, dnts)
+	fts := raytrace_integrand(dnts, rdndrp)
+
+	// at the top of the stratosphere
+	rs := RAYTRACE_EARTH_RADIUS + RAYTRACE_STRATOSPHERE
+	raytrace_stratosphere(rt, tt, dnt, gamal, rs, &dns, &rdndrs)
+	zs := raytrace_zenith(sk0, rs, dns)
+	fs := raytrace_integrand(dns, rdndrs)
+
+	// Simpson's rule over the zenith angle, troposphere (k = 1) then stratosphere (k = 2),
+	// doubling the intervals until two successive estimates agree
+	for k := 1; k <= 2; k++ {
+		var z0, zrange, fb, ff, r float64
+
+		if k == 1 {
+			z0, zrange, fb, ff, r = z_obs, zt-z_obs, f0, ft, r0
+		} else {
+			z0, zrange, fb, ff, r = zts, zs-zts, fts, fs, rt
+		}
+
+		refold := 1.0
+		is := 8
+		fo, fe := 0.0, 0.0
+		n := 1
+
+		for {
+			h := zrange / float64(is)
+
+			for i := 1; i < is; i += n {
+				sz := math.Sin(z0 + h*float64(i))
+				if sz > 1e-20 {
+					w := sk0 / sz
+					rg := r
+					dr := 1e6
+					for j := 0; math.Abs(dr) > 1.0 && j < 4; j++ {
+						if k == 1 {
+							raytrace_troposphere(r0, tdk, alpha, gamm2, delm2, c1, c2, c3, c4, c5, c6,
+								rg, &tg, &dn, &rdndr)
+						} else {
+							raytrace_stratosphere(rt, tt, dnt, gamal, rg, &dn, &rdndr)
+						}
+						dr = (rg*dn - w) / (dn + rdndr)
+						rg -= dr
+					}
+					r = rg
+				}
+
+				if k == 1 {
+					raytrace_troposphere(r0, tdk, alpha, gamm2, delm2, c1, c2, c3, c4, c5, c6,
+						r, &tg, &dn, &rdndr)
+				} else {
+					raytrace_stratosphere(rt, tt, dnt, gamal, r, &dn, &rdndr)
+				}
+
+				f := raytrace_integrand(dn, rdndr)
+				if n == 1 && i%2 == 0 {
+					fe += f
+				} else {
+					fo += f
+				}
+			}
+
+			refp = h * (fb + 4.0*fo + 2.0*fe + ff) / 3.0
+
+			if math.Abs(refp-refold) > tol && is < RAYTRACE_MAX_INTERVALS {
+				refold = refp
+				is += is
+				fe += fo
+				fo = 0.0
+				n = 2
+			} else {
+				break
+			}
+		}
+
+		if k == 1 {
+			reft = refp
+		}
+	}
+
+	return rad2deg(reft + refp)
+}
+
+func (m Spa_refraction_raytrace) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	return m.refraction(atm, 90.0-h)
+}
+
+func (m Spa_refraction_raytrace) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	h_min := 90.0 - RAYTRACE_MAX_ZENITH
+	if e0 < h_min-m.Apparent_correction(atm, h_min)-SUN_RADIUS {
+		return 0
+	}
+
+	h := e0
+	for i := 0; i < REFRACTION_ITERATIONS; i++ {
+		h = e0 + m.Apparent_correction(atm, h)
+	}
+
+	return h - e0
+}
@@ -0,0 +1,231 @@
+package gosolar
+
+import (
+	"math"
+)
+
+const (
+	REFRACTION_ITERATIONS = 10 //fixed point iterations used to convert between true and apparent elevation
+)
+
+// Spa_atmosphere holds the observer inputs a refraction model may use.
+type Spa_atmosphere struct {
+	Pressure      float64 // local pressure [millibars]
+	Temperature   float64 // local temperature [degrees Celsius]
+	Elevation     float64 // observer elevation [meters]
+	Latitude      float64 // observer latitude [degrees]
+	Atmos_refract float64 // atmospheric refraction at sunrise and sunset [degrees]
+}
+
+// Spa_refraction is an atmospheric refraction model. Set it as Spa_data.Refraction to use it
+// for the topocentric elevation and for the sun altitude at sunrise and sunset.
+type Spa_refraction interface {
+	// refraction [degrees] to add to the true (airless) elevation angle e0 [degrees]
+	Correction(atm Spa_atmosphere, e0 float64) float64
+	// refraction [degrees] of a body seen at the apparent elevation angle h [degrees]
+	Apparent_correction(atm Spa_atmosphere, h float64) float64
+}
+
+// Spa_refraction_nrel is the model of the NREL SPA report (Saemundsson's formula, applied while
+// the sun is above -(SUN_RADIUS + Atmos_refract), with Atmos_refract as the horizon refraction).
+type Spa_refraction_nrel struct{}
+
+// Spa_refraction_spa is the model used when Spa_data.Refraction is nil: the elevation is
+// corrected with the piecewise fit of Spa_refraction_piecewise and Atmos_refract is the
+// refraction at the horizon, for sunrise and sunset and for apparent elevations below it.
+type Spa_refraction_spa struct{}
+
+// Spa_refraction_piecewise is a rational fit below 15 degrees and 0.00452 P/T cot(e0) above,
+// zero below -2.5 degrees. It uses the refraction of the fit at the horizon (about 0.5686
+// degrees at 1010 mb and 10 degrees C) for apparent elevations below it.
+type Spa_refraction_piecewise struct{}
+
+// Spa_refraction_bennett is Bennett's formula for the apparent elevation (Meeus 16.3), scaled
+// for pressure and temperature; the true elevation is converted by iteration. Like the NREL
+// model, it uses the refraction at the horizon for apparent elevations below it.
+type Spa_refraction_bennett struct{}
+
+// Spa_refraction_saemundsson is Saemundsson's formula for the true elevation (Meeus 16.4),
+// scaled for pressure and temperature. Like the NREL model, it uses the refraction at the
+// horizon for apparent elevations below it.
+type Spa_refraction_saemundsson struct{}
+
+// Spa_refraction_none ignores refraction (airless, geometric elevation).
+type Spa_refraction_none struct{}
+
+// Spa_atmosphere_refraction applies a refraction model to one atmosphere, as the
+// coordinates.Refraction of Refract and Unrefract. A nil Model is Spa_refraction_spa, as for a
+// nil Spa_data.Refraction.
+type Spa_atmosphere_refraction struct {
+	Model      Spa_refraction
+	Atmosphere Spa_atmosphere
+}
+
+func spa_atmosphere(spa *Spa_data) Spa_atmosphere {
+	return Spa_atmosphere{Pressure: spa.Pressure, Temperature: spa.Temperature,
+		Elevation: spa.Elevation, Latitude: spa.Latitude, Atmos_refract: spa.Atmos_refract}
+}
+
+// the refraction model of structure (Spa_refraction_spa when nil)
+func refraction_model(spa *Spa_data) Spa_refraction {
+	if spa.Refraction == nil {
+		return Spa_refraction_spa{}
+	}
+
+	return spa.Refraction
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Refraction correction of the topocentric elevation angle e0 with the model of structure
+///////////////////////////////////////////////////////////////////////////////////////////////
+func refraction_correction(spa *Spa_data, e0 float64) float64 {
+	return refraction_model(spa).Correction(spa_atmosphere(spa), e0)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Dip of the apparent horizon below the horizontal for the observer height in structure
+// Note: 0.0293 sqrt(h) includes terrestrial refraction of the line of sight (1.76 arcmin/sqrt(m))
+///////////////////////////////////////////////////////////////////////////////////////////////
+func horizon_dip(spa *Spa_data) float64 {
+	return HORIZON_DIP * math.Sqrt(spa.Observer_height)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Refraction at the apparent horizon with the model of structure
+///////////////////////////////////////////////////////////////////////////////////////////////
+func horizon_refraction(spa *Spa_data, dip float64) float64 {
+	return refraction_model(spa).Apparent_correction(spa_atmosphere(spa), -dip)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Geocentric sun center altitude at sunrise and sunset (upper limb on the apparent horizon,
+// which is dipped for an elevated observer)
+///////////////////////////////////////////////////////////////////////////////////////////////
+func sun_rise_set_altitude(spa *Spa_data) float64 {
+	dip := horizon_dip(spa)
+
+	return -1 * (SUN_RADIUS + dip + horizon_refraction(spa, dip))
+}
+
+// pressure and temperature scaling of the mean refraction formulas (1010 mb, 10 degrees C)
+func refraction_pt_factor(atm Spa_atmosphere) float64 {
+	return (atm.Pressure / 1010.0) * (283.0 / (273.15 + atm.Temperature))
+}
+
+func saemundsson_refraction(atm Spa_atmosphere, e0 float64) float64 {
+	return refraction_pt_factor(atm) * 1.02 / (60.0 * math.Tan(deg2rad(e0+10.3/(e0+5.11))))
+}
+
+func bennett_refraction(atm Spa_atmosphere, h float64) float64 {
+	return refraction_pt_factor(atm) / (60.0 * math.Tan(deg2rad(h+7.31/(h+4.4))))
+}
+
+func (Spa_refraction_nrel) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	if e0 >= -1*(SUN_RADIUS+atm.Atmos_refract) {
+		// as in NREL's spa.c, with 273 rather than 273.15 in the temperature term
+		return (atm.Pressure / 1010.0) * (283.0 / (273.0 + atm.Temperature)) *
+			1.02 / (60.0 * math.Tan(deg2rad(e0+10.3/(e0+5.11))))
+	}
+
+	return 0
+}
+
+func (m Spa_refraction_nrel) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	if h <= 0 {
+		return atm.Atmos_refract
+	}
+
+	e0 := h
+	for i := 0; i < REFRACTION_ITERATIONS; i++ {
+		e0 = h - m.Correction(atm, e0)
+	}
+
+	return h - e0
+}
+
+func (Spa_refraction_spa) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	return atmospheric_refraction_correction(atm.Pressure, atm.Temperature, atm.Atmos_refract, e0)
+}
+
+func (Spa_refraction_spa) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	if h <= 0 {
+		return atm.Atmos_refract
+	}
+
+	return Spa_refraction_piecewise{}.Apparent_correction(atm, h)
+}
+
+func (Spa_refraction_piecewise) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	return atmospheric_refraction_correction(atm.Pressure, atm.Temperature, atm.Atmos_refract, e0)
+}
+
+func (m Spa_refraction_piecewise) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	h = math.Max(h, 0)
+
+	e0 := h
+	for i := 0; i < REFRACTION_ITERATIONS; i++ {
+		e0 = h - m.Correction(atm, e0)
+	}
+
+	return h - e0
+}
+
+func (m Spa_refraction_bennett) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	if e0 < -1*(SUN_RADIUS+m.Apparent_correction(atm, 0)) {
+		return 0
+	}
+
+	h := e0
+	for i := 0; i < REFRACTION_ITERATIONS; i++ {
+		h = e0 + bennett_refraction(atm, h)
+	}
+
+	return h - e0
+}
+
+func (Spa_refraction_bennett) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	return bennett_refraction(atm, math.Max(h, 0))
+}
+
+func (m Spa_refraction_saemundsson) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	if e0 < -1*(SUN_RADIUS+m.Apparent_correction(atm, 0)) {
+		return 0
+	}
+
+	return saemundsson_refraction(atm, e0)
+}
+
+func (Spa_refraction_saemundsson) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	h = math.Max(h, 0)
+
+	e0 := h
+	for i := 0; i < REFRACTION_ITERATIONS; i++ {
+		e0 = h - saemundsson_refraction(atm, e0)
+	}
+
+	return h - e0
+}
+
+func (Spa_refraction_none) Correction(atm Spa_atmosphere, e0 float64) float64 {
+	return 0
+}
+
+func (Spa_refraction_none) Apparent_correction(atm Spa_atmosphere, h float64) float64 {
+	return 0
+}
+
+func (r Spa_atmosphere_refraction) model() Spa_refraction {
+	if r.Model == nil {
+		return Spa_refraction_spa{}
+	}
+
+	return r.Model
+}
+
+func (r Spa_atmosphere_refraction) Correction(e0 float64) float64 {
+	return r.model().Correction(r.Atmosphere, e0)
+}
+
+func (r Spa_atmosphere_refraction) Apparent_correction(h float64) float64 {
+	return r.model().Apparent_correction(r.Atmosphere, h)
+}
@@ -0,0 +1,191 @@
+package gosolar
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRefraction_models(t *testing.T) {
+	// the standard conditions of the mean refraction formulas
+	std := Spa_atmosphere{Pressure: 1010, Temperature: 10, Atmos_refract: 0.5667}
+
+	models := []struct {
+		name  string
+		model Spa_refraction
+	}{
+		{"spa", Spa_refraction_spa{}},
+		{"nrel", Spa_refraction_nrel{}},
+		{"piecewise", Spa_refraction_piecewise{}},
+		{"bennett", Spa_refraction_bennett{}},
+		{"saemundsson", Spa_refraction_saemundsson{}},
+	}
+
+	// Bennett's formula refracts the horizon by 34.5' (Meeus, chapter 16), Saemundsson's agrees
+	// with it within 0.1' and the piecewise fit, made for higher elevations, within 0.2' from 5
+	// degrees up [arcminutes]
+	bennett := Spa_refraction_bennett{}
+	if r := 60 * bennett.Apparent_correction(std, 0); math.Abs(r-34.5) > 0.05 {
+		t.Errorf("bennett: refraction %.2f' at the horizon, want 34.5'", r)
+	}
+	for h := 0.0; h <= 90; h += 0.5 {
+		r := 60 * bennett.Apparent_correction(std, h)
+		for _, tt := range []struct {
+			model    Spa_refraction
+			from, to float64
+		}{
+			{Spa_refraction_saemundsson{}, 0, 0.1},
+			{Spa_refraction_nrel{}, 0.5, 0.1},
+			{Spa_refraction_piecewise{}, 5, 0.2},
+		} {
+			if got := 60 * tt.model.Apparent_correction(std, h); h >= tt.from && math.Abs(got-r) > tt.to {
+				t.Errorf("%T at %v: refraction %.3f', bennett %.3f'", tt.model, h, got, r)
+			}
+		}
+	}
+
+	for _, m := range models {
+		// Correction and Apparent_correction are inverses: e0 + R(e0) is seen at h with R(h)
+		for e0 := 0.5; e0 < 90; e0 += 0.5 {
+			r := m.model.Correction(std, e0)
+			if back := m.model.Apparent_correction(std, e0+r); math.Abs(back-r) > 1e-6 {
+				t.Errorf("%s at %v: correction %v, apparent correction %v", m.name, e0, r, back)
+			}
+		}
+
+		// twice the pressure refracts twice as much, a colder atmosphere more
+		dense, cold := std, std
+		dense.Pressure *= 2
+		cold.Temperature = -20
+		r, rd, rc := m.model.Correction(std, 20), m.model.Correction(dense, 20), m.model.Correction(cold, 20)
+		if math.Abs(rd/r-2) > 0.01 || rc <= r {
+			t.Errorf("%s: correction %v at 1010 mb, %v at 2020 mb, %v at -20 C", m.name, r, rd, rc)
+		}
+
+		// nothing is refracted well below the horizon
+		if r := m.model.Correction(std, -5); r != 0 {
+			t.Errorf("%s: correction %v at -5 degrees, want 0", m.name, r)
+		}
+
+		// below the horizon an elevated observer sees the refraction of the horizon, not the
+		// singularity of the formulas a few degrees down
+		horizon := m.model.Apparent_correction(std, 0)
+		for _, h := range []float64{-0.5, -2, -4.4, -5.11, -10} {
+			if r := m.model.Apparent_correction(std, h); math.IsNaN(r) || math.Abs(r-horizon) > 0.3 {
+				t.Errorf("%s at %v: apparent correction %v, horizon %v", m.name, h, r, horizon)
+			}
+		}
+	}
+
+	none := Spa_refraction_none{}
+	if none.Correction(std, 0) != 0 || none.Apparent_correction(std, 0) != 0 {
+		t.Error("none: nonzero refraction")
+	}
+
+	// the NREL model stops at -(SUN_RADIUS + Atmos_refract) and uses Atmos_refract at the horizon
+	nrel := Spa_refraction_nrel{}
+	if nrel.Correction(std, -0.83) == 0 || nrel.Correction(std, -0.84) != 0 {
+		t.Errorf("nrel: correction %v at -0.83, %v at -0.84", nrel.Correction(std, -0.83), nrel.Correction(std, -0.84))
+	}
+	if r := nrel.Apparent_correction(std, -1); r != std.Atmos_refract {
+		t.Errorf("nrel: apparent correction %v below the horizon, want %v", r, std.Atmos_refract)
+	}
+
+	// the model of structure is used for the position and for the rise/set altitude
+	spa := example_spa_data()
+	Spa_calculate(&spa)
+	for _, m := range append(models, struct {
+		name  string
+		model Spa_refraction
+	}{"none", none}) {
+		with := example_spa_data()
+		with.Refraction = m.model
+		with.Observer_height = 10000
+		if code := Spa_calculate(&with); code != 0 {
+			t.Fatalf("%s: error code %d", m.name, code)
+		}
+
+		atm := spa_atmosphere(&with)
+		if d := with.e - with.e0 - m.model.Correction(atm, with.e0); math.Abs(d) > 1e-12 {
+			t.Errorf("%s: elevation %v is not refracted by the model (%v)", m.name, with.e, d)
+		}
+		if h0 := sun_rise_set_altitude(&with); math.IsNaN(h0) || h0 > -SUN_RADIUS-horizon_dip(&with) ||
+			h0 < -SUN_RADIUS-horizon_dip(&with)-1 {
+			t.Errorf("%s: rise/set altitude %v for a 10 km observer height", m.name, h0)
+		}
+		if math.IsNaN(with.Sunrise) || math.IsNaN(with.Sunset) || with.Sunrise >= spa.Sunrise {
+			t.Errorf("%s: sunrise %v from 10 km, %v at the ground", m.name, with.Sunrise, spa.Sunrise)
+		}
+	}
+}
+
+func TestRefraction_nil(t *testing.T) {
+	// a nil Refraction is Spa_refraction_spa for the position and for sunrise and sunset
+	spa, with := example_spa_data(), example_spa_data()
+	with.Refraction = Spa_refraction_spa{}
+	Spa_calculate(&spa)
+	Spa_calculate(&with)
+	if spa.Zenith != with.Zenith || spa.Sunrise != with.Sunrise || spa.Sunset != with.Sunset {
+		t.Errorf("nil: zenith %v, sunrise %v, sunset %v; Spa_refraction_spa: %v, %v, %v", spa.Zenith,
+			spa.Sunrise, spa.Sunset, with.Zenith, with.Sunrise, with.Sunset)
+	}
+
+	// and the model of a nil Spa_atmosphere_refraction.Model
+	r := Spa_atmosphere_refraction{Atmosphere: spa_atmosphere(&spa)}
+	if got := r.Apparent_correction(-horizon_dip(&spa)); got != spa.Atmos_refract {
+		t.Errorf("Spa_atmosphere_refraction: refraction %v at the horizon, want Atmos_refract %v", got, spa.Atmos_refract)
+	}
+	if got, want := r.Correction(spa.e0), spa.e-spa.e0; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Spa_atmosphere_refraction: correction %v, want %v", got, want)
+	}
+}
+
+func TestRefraction_raytrace(t *testing.T) {
+	// the test case of SLALIB's sla_REFRO: ZOBS 1.4 rad, HM 3456.7 m, TDK 280 K, PMB 678.9 mb,
+	// RH 0.9, PHI -0.3 rad, TLR 0.006 K/m, EPS 1e-9, optical (0.55 um) and radio [radians]
+	atm := Spa_atmosphere{Pressure: 678.9, Temperature: 280 - 273.15, Elevation: 3456.7, Latitude: rad2deg(-0.3)}
+	for _, tt := range []struct {
+		wavelength, want float64
+	}{
+		{0.55, 0.00106715763018568},
+		{1000, 0.001296416185295403},
+	} {
+		m := Spa_refraction_raytrace{Lapse_rate: 0.006, Humidity: 0.9, Wavelength: tt.wavelength, Tolerance: 1e-9}
+		if got := deg2rad(m.refraction(atm, rad2deg(1.4))); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("wavelength %v: refraction %.17g rad, want %.17g", tt.wavelength, got, tt.want)
+		}
+	}
+
+	// a dry standard atmosphere refracts the horizon by about 34', like the mean formulas, and
+	// agrees with Bennett's within 3% from 15 degrees up
+	raytrace := Spa_refraction_raytrace{}
+	std := Spa_atmosphere{Pressure: 1010, Temperature: 10, Atmos_refract: 0.5667}
+	if r := 60 * raytrace.Apparent_correction(std, 0); math.Abs(r-34) > 1.5 {
+		t.Errorf("refraction %.2f' at the horizon, want about 34'", r)
+	}
+	for h := 15.0; h < 90; h += 5 {
+		r, b := 60*raytrace.Apparent_correction(std, h), 60*Spa_refraction_bennett{}.Apparent_correction(std, h)
+		if math.Abs(r/b-1) > 0.03 {
+			t.Errorf("refraction %.3f' at %v, bennett %.3f'", r, h, b)
+		}
+	}
+
+	// the ray is traced below the horizon, down to the apparent elevation -3 degrees
+	prev := 0.0
+	for h := 0.0; h >= -3; h -= 0.5 {
+		r := raytrace.Apparent_correction(std, h)
+		if math.IsNaN(r) || r <= prev {
+			t.Errorf("refraction %v at %v is not above %v", r, h, prev)
+		}
+		prev = r
+	}
+
+	for _, e0 := range []float64{-1, 0, 1, 10, 45} {
+		r := raytrace.Correction(std, e0)
+		if back := raytrace.Apparent_correction(std, e0+r); math.Abs(back-r) > 1e-5 {
+			t.Errorf("at %v: correction %v, apparent correction %v", e0, r, back)
+		}
+	}
+	if r := raytrace.Correction(std, -6); r != 0 {
+		t.Errorf("correction %v at -6 degrees, want 0", r)
+	}
+}
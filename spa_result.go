@@ -0,0 +1,139 @@
+package gosolar
+
+import (
+	"time"
+)
+
+// Spa_result holds every intermediate and final output value of one SPA calculation.
+// Values that the requested Function does not calculate are left at zero: Incidence needs
+// SPA_ZA_INC or SPA_ALL, and Eot, Srha, Ssha, Sta, Suntransit, Sunrise and Sunset need
+// SPA_ZA_RTS or SPA_ALL. Sunrise/transit/set values are -99999 when the sun does not rise
+// or set on that day.
+type Spa_result struct {
+	//-----------------Intermediate OUTPUT VALUES--------------------
+
+	Jd      float64 //Julian day
+	Jc      float64 //Julian century
+	Delta_t float64 //Delta T used (estimated when Delta_t_auto is set) [seconds]
+
+	Jde float64 //Julian ephemeris day
+	Jce float64 //Julian ephemeris century
+	Jme float64 //Julian ephemeris millennium
+
+	L float64 //earth heliocentric longitude [degrees]
+	B float64 //earth heliocentric latitude [degrees]
+	R float64 //earth radius vector [Astronomical Units, AU]
+
+	Theta float64 //geocentric longitude [degrees]
+	Beta  float64 //geocentric latitude [degrees]
+
+	X0 float64 //mean elongation (moon-sun) [degrees]
+	X1 float64 //mean anomaly (sun) [degrees]
+	X2 float64 //mean anomaly (moon) [degrees]
+	X3 float64 //argument latitude (moon) [degrees]
+	X4 float64 //ascending longitude (moon) [degrees]
+
+	Del_psi     float64 //nutation longitude [degrees]
+	Del_epsilon float64 //nutation obliquity [degrees]
+	Epsilon0    float64 //ecliptic mean obliquity [arc seconds]
+	Epsilon     float64 //ecliptic true obliquity  [degrees]
+
+	Del_tau float64 //aberration correction [degrees]
+	Lamda   float64 //apparent sun longitude [degrees]
+	Nu0     float64 //Greenwich mean sidereal time [degrees]
+	Nu      float64 //Greenwich sidereal time [degrees]
+
+	Alpha float64 //geocentric sun right ascension [degrees]
+	Delta float64 //geocentric sun declination [degrees]
+
+	H           float64 //observer hour angle [degrees]
+	Xi          float64 //sun equatorial horizontal parallax [degrees]
+	Del_alpha   float64 //sun right ascension parallax [degrees]
+	Delta_prime float64 //topocentric sun declination [degrees]
+	Alpha_prime float64 //topocentric sun right ascension [degrees]
+	H_prime     float64 //topocentric local hour angle [degrees]
+
+	E0    float64 //topocentric elevation angle (uncorrected) [degrees]
+	Del_e float64 //atmospheric refraction correction [degrees]
+	E     float64 //topocentric elevation angle (corrected) [degrees]
+
+	Eot  float64 //equation of time [minutes]
+	Srha float64 //sunrise hour angle [degrees]
+	Ssha float64 //sunset hour angle [degrees]
+	Sta  float64 //sun transit altitude [degrees]
+
+	//---------------------Final OUTPUT VALUES------------------------
+
+	Zenith        float64 //topocentric zenith angle [degrees]
+	Azimuth_astro float64 //topocentric azimuth angle (westward from south) [for astronomers]
+	Azimuth       float64 //topocentric azimuth angle (eastward from north) [for navigators and solar radiation]
+	Incidence     float64 //surface incidence angle [degrees]
+	Obstructed    bool    //sun center (refraction corrected) is below the horizon profile
+
+	Suntransit float64 //local sun transit time (or solar noon) [fractional hour]
+	Sunrise    float64 //local sunrise time (+/- 30 seconds) [fractional hour]
+	Sunset     float64 //local sunset time (+/- 30 seconds) [fractional hour]
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Copy all output values from the structure into a result
+///////////////////////////////////////////////////////////////////////////////////////////
+func spa_result(spa *Spa_data) Spa_result {
+	return Spa_result{
+		Jd: spa.Jd, Jc: spa.jc, Delta_t: spa.Delta_t,
+		Jde: spa.jde, Jce: spa.jce, Jme: spa.jme,
+		L: spa.L, B: spa.B, R: spa.R,
+		Theta: spa.theta, Beta: spa.beta,
+		X0: spa.x0, X1: spa.x1, X2: spa.x2, X3: spa.x3, X4: spa.x4,
+		Del_psi: spa.Del_psi, Del_epsilon: spa.Del_epsilon, Epsilon0: spa.epsilon0, Epsilon: spa.Epsilon,
+		Del_tau: spa.del_tau, Lamda: spa.lamda, Nu0: spa.nu0, Nu: spa.nu,
+		Alpha: spa.alpha, Delta: spa.delta,
+		H: spa.H, Xi: spa.xi, Del_alpha: spa.del_alpha,
+		Delta_prime: spa.delta_prime, Alpha_prime: spa.alpha_prime, H_prime: spa.h_prime,
+		E0: spa.e0, Del_e: spa.del_e, E: spa.e,
+		Eot: spa.eot, Srha: spa.srha, Ssha: spa.ssha, Sta: spa.sta,
+		Zenith: spa.Zenith, Azimuth_astro: spa.azimuth_astro, Azimuth: spa.Azimuth, Incidence: spa.Incidence,
+		Obstructed: spa.Obstructed,
+		Suntransit: spa.suntransit, Sunrise: spa.Sunrise, Sunset: spa.Sunset,
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters for the inputs in structure and return them as a result
+// Note: in is passed by value, so the caller's structure (inputs and outputs) is not changed;
+// like Spa_calculate, it makes no heap allocations for valid inputs and is safe for concurrent use
+///////////////////////////////////////////////////////////////////////////////////////////
+func Spa_compute(in Spa_data) (Spa_result, error) {
+	if err := Spa_validate(&in); err != nil {
+		return Spa_result{}, err
+	}
+	if err := apply_earth_orientation(&in); err != nil {
+		return Spa_result{}, err
+	}
+
+	calculate_all(&in)
+
+	return spa_result(&in), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters for the instant t and return them as a result
+// Note: Year, Month, Day, Hour, Minute, Second and Timezone of in are ignored (taken from t);
+// a leap second folded into the next minute by time.Time is calculated one second late (see
+// Spa_calculate_time and Spa_compute_time_leap)
+///////////////////////////////////////////////////////////////////////////////////////////
+func Spa_compute_time(t time.Time, in Spa_data) (Spa_result, error) {
+	spa_set_time(&in, t)
+
+	return Spa_compute(in)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters for the leap second that ends at the instant t
+// Note: See Spa_calculate_time_leap; in needs a leap second table with one there
+///////////////////////////////////////////////////////////////////////////////////////////
+func Spa_compute_time_leap(t time.Time, in Spa_data) (Spa_result, error) {
+	spa_set_time_leap(&in, t)
+
+	return Spa_compute(in)
+}
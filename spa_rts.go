@@ -0,0 +1,187 @@
+package gosolar
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	SPA_RTS_NORMAL      = iota //the sun rises and sets
+	SPA_RTS_POLAR_DAY          //the sun stays above the horizon all day (midnight sun)
+	SPA_RTS_POLAR_NIGHT        //the sun stays below the horizon all day
+)
+
+// Spa_rts holds the sun rise, transit and set of one local calendar day.
+type Spa_rts struct {
+	State   int       // SPA_RTS_NORMAL, SPA_RTS_POLAR_DAY or SPA_RTS_POLAR_NIGHT
+	Sunrise time.Time // local sunrise (+/- 30 seconds), zero if the sun does not rise that day
+	Transit time.Time // local sun transit (solar noon), also set in the polar states
+	Sunset  time.Time // local sunset (+/- 30 seconds), zero if the sun does not set that day
+}
+
+const (
+	SUN_RTS_SAMPLES   = 288           //altitude samples per day used to bracket rise and set (every 5 minutes)
+	SUN_RTS_CONVERGED = 0.1 / 86400.0 //bracket width at which a rise or set time has converged [fraction of day]
+)
+
+// geocentric sun altitude less h0_prime at m [fraction of day from 0h UT], interpolated as SPA does
+func sun_rts_altitude_offset(spa *Spa_data, day *sun_rts_day, h0_prime, m float64) float64 {
+	nu := day.nu + 360.985647*m
+	n := m + spa.Delta_t/86400.0
+
+	alpha_prime := rts_alpha_delta_prime(day.alpha[:], n)
+	delta_prime := rts_alpha_delta_prime(day.delta[:], n)
+	h_prime := limit_degrees180pm(nu + spa.Longitude - alpha_prime)
+
+	return rts_sun_altitude(spa.Latitude, delta_prime, h_prime) - h0_prime
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Find transit and the times the sun center crosses altitude h0_prime on the local day of t
+//
+// SPA interpolates rise/transit/set for a UT day, and an event of the observer's local day can
+// belong to the UT day before or after. All three UT days around the local date are solved and
+// only the events inside the local day are kept.
+//
+// SPA's single rise/set correction divides by sin H', which vanishes when the sun only grazes
+// the altitude (near the polar day and night) and lets an event close to 0h UT slip into the
+// wrong day. Instead, the interpolated altitude is sampled SUN_RTS_SAMPLES times a day and
+// every crossing is bisected. A graze shorter than one sample step may be missed.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func spa_local_day_events(t time.Time, spa *Spa_data, h0_prime float64, out *Spa_rts) {
+	var day sun_rts_day
+	var ev sun_rts_events
+	var i, k int
+	var above, above_set bool
+
+	loc := t.Location()
+	year, month, mday := t.Date()
+	day_start := time.Date(year, month, mday, 0, 0, 0, 0, loc)
+	day_end := day_start.AddDate(0, 0, 1)
+
+	in_day := func(e time.Time) bool {
+		return !e.Before(day_start) && e.Before(day_end)
+	}
+
+	*out = Spa_rts{}
+
+	for k = -1; k <= 1; k++ {
+		ut_day := time.Date(year, month, mday+k, 0, 0, 0, 0, time.UTC)
+		at := func(m float64) time.Time {
+			return ut_day.Add(time.Duration(m * 86400e9)).In(loc)
+		}
+
+		sun_rts := *spa
+		sun_rts.Year, sun_rts.Month, sun_rts.Day = ut_day.Year(), int(ut_day.Month()), ut_day.Day()
+
+		calculate_sun_rts_day(&sun_rts, &day)
+		calculate_sun_rts_events(&sun_rts, &day, h0_prime, &ev)
+
+		if transit := at(ev.m[SUN_TRANSIT]); in_day(transit) && out.Transit.IsZero() {
+			out.Transit = transit
+		}
+
+		m0 := 0.0
+		f0 := sun_rts_altitude_offset(&sun_rts, &day, h0_prime, m0)
+		for i = 1; i <= SUN_RTS_SAMPLES; i++ {
+			m1 := float64(i) / SUN_RTS_SAMPLES
+			f1 := sun_rts_altitude_offset(&sun_rts, &day, h0_prime, m1)
+
+			if !above_set && in_day(at(m1)) {
+				above, above_set = f1 > 0, true
+			}
+
+			if math.Signbit(f0) != math.Signbit(f1) {
+				a, b, fa := m0, m1, f0
+				for b-a > SUN_RTS_CONVERGED {
+					m := (a + b) / 2
+					fm := sun_rts_altitude_offset(&sun_rts, &day, h0_prime, m)
+					if math.Signbit(fm) == math.Signbit(fa) {
+						a, fa = m, fm
+					} else {
+						b = m
+					}
+				}
+
+				if e := at((a + b) / 2); in_day(e) {
+					if f1 > f0 && out.Sunrise.IsZero() {
+						out.Sunrise = e
+					} else if f1 < f0 && out.Sunset.IsZero() {
+						out.Sunset = e
+					}
+				}
+			}
+
+			m0, f0 = m1, f1
+		}
+	}
+
+	switch {
+	case !out.Sunrise.IsZero() || !out.Sunset.IsZero():
+		out.State = SPA_RTS_NORMAL
+	case above:
+		out.State = SPA_RTS_POLAR_DAY
+	default:
+		out.State = SPA_RTS_POLAR_NIGHT
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Set up and check the inputs for the event solvers of the local calendar day of t
+///////////////////////////////////////////////////////////////////////////////////////////////
+func spa_rts_inputs(t time.Time, spa *Spa_data) error {
+	spa_set_time(spa, t)
+	spa.Function = SPA_ZA_RTS
+
+	if err := Spa_validate(spa); err != nil {
+		return err
+	}
+	if err := apply_earth_orientation(spa); err != nil {
+		return err
+	}
+	calculate_julian_day(spa)
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate sunrise, sun transit and sunset of the local calendar day of t
+// Note: The observer inputs of in are used as for Spa_compute_time, Function is ignored.
+// Events are returned in t's location; an event that SPA's UT day places on the previous or
+// next calendar day is taken from the neighbouring UT day instead of being wrapped around.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_sun_rts(t time.Time, in Spa_data) (Spa_rts, error) {
+	var rts Spa_rts
+
+	if err := spa_rts_inputs(t, &in); err != nil {
+		return rts, err
+	}
+
+	spa_local_day_events(t, &in, sun_rise_set_altitude(&in), &rts)
+
+	return rts, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate the times the sun center crosses a geocentric altitude on the local day of t
+//
+// This is the generalized rise/set solver: Sunrise and Sunset of the result hold the morning
+// (rising) and evening (setting) crossing of altitude [degrees, -90 to 90], and State tells
+// whether the sun stays above (SPA_RTS_POLAR_DAY) or below (SPA_RTS_POLAR_NIGHT) it all day.
+// No refraction is applied; Spa_sun_rts uses -(SUN_RADIUS + refraction at the horizon).
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_sun_altitude_events(t time.Time, in Spa_data, altitude float64) (Spa_rts, error) {
+	var rts Spa_rts
+
+	if err := spa_rts_inputs(t, &in); err != nil {
+		return rts, err
+	}
+	if math.Abs(altitude) > 90 {
+		return rts, &Spa_validation_error{Errors: []*Spa_range_error{{Field: "altitude", Value: altitude,
+			Min: -90, Max: 90, Range: "-90 to 90 degrees"}}}
+	}
+
+	spa_local_day_events(t, &in, altitude, &rts)
+
+	return rts, nil
+}
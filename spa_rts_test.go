@@ -0,0 +1,201 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// observer at sea level under standard conditions, rise/set at the NREL 0.5667 degree refraction
+func rts_site(latitude, longitude float64) Spa_data {
+	return Spa_data{Latitude: latitude, Longitude: longitude, Pressure: 1013.25, Temperature: 10,
+		Atmos_refract: 0.5667, Delta_t_auto: true}
+}
+
+func load_location(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	return loc
+}
+
+func TestSpa_sun_rts(t *testing.T) {
+	reykjavik := load_location(t, "Atlantic/Reykjavik")
+	anchorage := load_location(t, "America/Anchorage")
+
+	// USNO one-day tables, rounded to the minute
+	tests := []struct {
+		name            string
+		in              Spa_data
+		day             time.Time
+		sunrise, sunset time.Time
+	}{
+		{"Reykjavik winter solstice", rts_site(64.1466, -21.9426), time.Date(2023, 12, 21, 12, 0, 0, 0, reykjavik),
+			time.Date(2023, 12, 21, 11, 22, 0, 0, reykjavik), time.Date(2023, 12, 21, 15, 29, 0, 0, reykjavik)},
+		{"Reykjavik summer solstice, the sun sets after midnight", rts_site(64.1466, -21.9426),
+			time.Date(2023, 6, 21, 12, 0, 0, 0, reykjavik),
+			time.Date(2023, 6, 21, 2, 55, 0, 0, reykjavik), time.Date(2023, 6, 21, 0, 3, 0, 0, reykjavik)},
+		{"Fairbanks winter solstice", rts_site(64.8378, -147.7164), time.Date(2023, 12, 21, 12, 0, 0, 0, anchorage),
+			time.Date(2023, 12, 21, 10, 58, 0, 0, anchorage), time.Date(2023, 12, 21, 14, 40, 0, 0, anchorage)},
+		{"Fairbanks summer solstice, the sun sets after midnight", rts_site(64.8378, -147.7164),
+			time.Date(2023, 6, 21, 12, 0, 0, 0, anchorage),
+			time.Date(2023, 6, 21, 2, 58, 0, 0, anchorage), time.Date(2023, 6, 21, 0, 48, 0, 0, anchorage)},
+	}
+
+	for _, tt := range tests {
+		rts, err := Spa_sun_rts(tt.day, tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rts.State != SPA_RTS_NORMAL {
+			t.Errorf("%s: state %d, want SPA_RTS_NORMAL", tt.name, rts.State)
+		}
+		if d := rts.Sunrise.Sub(tt.sunrise); d.Abs() > time.Minute {
+			t.Errorf("%s: sunrise %v, want %v", tt.name, rts.Sunrise, tt.sunrise)
+		}
+		if d := rts.Sunset.Sub(tt.sunset); d.Abs() > time.Minute {
+			t.Errorf("%s: sunset %v, want %v", tt.name, rts.Sunset, tt.sunset)
+		}
+		if rts.Transit.Before(rts.Sunrise) {
+			t.Errorf("%s: transit %v before sunrise %v", tt.name, rts.Transit, rts.Sunrise)
+		}
+	}
+
+	// the report example: sunrise 6:12:43 and sunset 17:20:19 local standard time. SPA's events
+	// are those of the UT day, which begins at 17:00 MST, so its sunset is the one of the
+	// evening before the local date.
+	mst := time.FixedZone("MST", -7*3600)
+	spa := example_spa_data()
+	rts, err := Spa_sun_rts(time.Date(2003, 10, 17, 12, 0, 0, 0, mst), spa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := Spa_sun_rts(time.Date(2003, 10, 16, 12, 0, 0, 0, mst), spa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"sunrise", rts.Sunrise, time.Date(2003, 10, 17, 6, 12, 43, 0, mst)},
+		{"sunset", before.Sunset, time.Date(2003, 10, 16, 17, 20, 19, 0, mst)},
+	} {
+		if d := ev.got.Sub(ev.want); d.Abs() > 2*time.Second {
+			t.Errorf("report example %s %v, want %v", ev.name, ev.got, ev.want)
+		}
+	}
+}
+
+func TestSpa_sun_rts_polar(t *testing.T) {
+	anchorage := load_location(t, "America/Anchorage")
+	oslo := load_location(t, "Europe/Oslo")
+	longyearbyen := load_location(t, "Arctic/Longyearbyen")
+
+	utqiagvik := rts_site(71.2906, -156.7886)
+	tromso := rts_site(69.6496, 18.9560)
+	svalbard := rts_site(78.2232, 15.6267)
+	south := rts_site(-75, 0)
+
+	// Utqiagvik: the sun sets on November 18 and rises again on January 23, and the midnight
+	// sun lasts from May 10 until it sets again on August 2 (NWS Fairbanks)
+	tests := []struct {
+		name      string
+		in        Spa_data
+		day       time.Time
+		state     int
+		rise, set bool
+	}{
+		{"Utqiagvik last sunset", utqiagvik, time.Date(2023, 11, 18, 12, 0, 0, 0, anchorage), SPA_RTS_NORMAL, true, true},
+		{"Utqiagvik first day of polar night", utqiagvik, time.Date(2023, 11, 19, 12, 0, 0, 0, anchorage), SPA_RTS_POLAR_NIGHT, false, false},
+		{"Utqiagvik last day of polar night", utqiagvik, time.Date(2024, 1, 22, 12, 0, 0, 0, anchorage), SPA_RTS_POLAR_NIGHT, false, false},
+		{"Utqiagvik first sunrise", utqiagvik, time.Date(2024, 1, 23, 12, 0, 0, 0, anchorage), SPA_RTS_NORMAL, true, true},
+		{"Utqiagvik last set before the midnight sun", utqiagvik, time.Date(2024, 5, 10, 12, 0, 0, 0, anchorage), SPA_RTS_NORMAL, true, true},
+		{"Utqiagvik first day of midnight sun", utqiagvik, time.Date(2024, 5, 11, 12, 0, 0, 0, anchorage), SPA_RTS_POLAR_DAY, false, false},
+		{"Utqiagvik last day of midnight sun", utqiagvik, time.Date(2023, 8, 1, 12, 0, 0, 0, anchorage), SPA_RTS_POLAR_DAY, false, false},
+		{"Utqiagvik set after the midnight sun", utqiagvik, time.Date(2023, 8, 2, 12, 0, 0, 0, anchorage), SPA_RTS_NORMAL, true, true},
+		{"Tromso polar night", tromso, time.Date(2023, 12, 21, 12, 0, 0, 0, oslo), SPA_RTS_POLAR_NIGHT, false, false},
+		{"Tromso midnight sun", tromso, time.Date(2023, 6, 21, 12, 0, 0, 0, oslo), SPA_RTS_POLAR_DAY, false, false},
+		{"Tromso equinox", tromso, time.Date(2023, 3, 20, 12, 0, 0, 0, oslo), SPA_RTS_NORMAL, true, true},
+		{"Longyearbyen polar night", svalbard, time.Date(2023, 11, 15, 12, 0, 0, 0, longyearbyen), SPA_RTS_POLAR_NIGHT, false, false},
+		{"Longyearbyen midnight sun", svalbard, time.Date(2023, 5, 1, 12, 0, 0, 0, longyearbyen), SPA_RTS_POLAR_DAY, false, false},
+		{"75 S midsummer", south, time.Date(2023, 12, 21, 12, 0, 0, 0, time.UTC), SPA_RTS_POLAR_DAY, false, false},
+		{"75 S midwinter", south, time.Date(2023, 6, 21, 12, 0, 0, 0, time.UTC), SPA_RTS_POLAR_NIGHT, false, false},
+	}
+
+	for _, tt := range tests {
+		rts, err := Spa_sun_rts(tt.day, tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rts.State != tt.state {
+			t.Errorf("%s: state %d, want %d", tt.name, rts.State, tt.state)
+		}
+		if rts.Sunrise.IsZero() == tt.rise || rts.Sunset.IsZero() == tt.set {
+			t.Errorf("%s: sunrise %v, sunset %v, want rise %v and set %v", tt.name, rts.Sunrise, rts.Sunset,
+				tt.rise, tt.set)
+		}
+		if rts.Transit.IsZero() {
+			t.Errorf("%s: no transit", tt.name)
+		}
+	}
+}
+
+func TestSpa_sun_altitude_events(t *testing.T) {
+	loc := time.FixedZone("", 3600)
+
+	// every event is where the geocentric sun center reaches the altitude, also when it only
+	// just grazes it near the polar day or night and when the event falls close to 0h UT
+	for _, tt := range []struct {
+		latitude, longitude, altitude float64
+		day                           time.Time
+	}{
+		{78.2232, 15, -12, time.Date(2023, 3, 20, 12, 0, 0, 0, loc)},
+		{78.2232, 15, -12, time.Date(2023, 3, 19, 12, 0, 0, 0, loc)},
+		{70, 15, -0.8333, time.Date(2023, 7, 28, 12, 0, 0, 0, loc)},
+		{50, 15, -18, time.Date(2023, 7, 14, 12, 0, 0, 0, loc)},
+		{55, -52, -9, time.Date(2023, 8, 19, 12, 0, 0, 0, time.FixedZone("", -3*3600))},
+		{-70, 15, -6, time.Date(2023, 10, 30, 12, 0, 0, 0, loc)},
+		{-70, 15, -6, time.Date(2023, 10, 31, 12, 0, 0, 0, loc)},
+	} {
+		in := rts_site(tt.latitude, tt.longitude)
+
+		rts, err := Spa_sun_altitude_events(tt.day, in, tt.altitude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rts.Sunrise.IsZero() && rts.Sunset.IsZero() {
+			t.Errorf("%v at %v: no events on %v", tt.latitude, tt.altitude, tt.day)
+		}
+
+		for _, e := range []time.Time{rts.Sunrise, rts.Sunset} {
+			if e.IsZero() {
+				continue
+			}
+			if y, m, d := e.Date(); y != tt.day.Year() || m != tt.day.Month() || d != tt.day.Day() {
+				t.Errorf("%v at %v: event %v is not on %v", tt.latitude, tt.altitude, e, tt.day)
+			}
+
+			var spa Spa_data
+			if err := spa_sun_position_at(e, &in, &spa); err != nil {
+				t.Fatal(err)
+			}
+			if h := rts_sun_altitude(spa.Latitude, spa.delta, spa.H); math.Abs(h-tt.altitude) > 0.005 {
+				t.Errorf("%v at %v: altitude %v at %v", tt.latitude, tt.altitude, h, e)
+			}
+		}
+	}
+
+	var validation *Spa_validation_error
+	if _, err := Spa_sun_altitude_events(time.Now(), rts_site(40, 0), 91); !errors.As(err, &validation) {
+		t.Errorf("altitude 91: error %v, want a validation error", err)
+	}
+	if _, err := Spa_sun_rts(time.Now(), rts_site(91, 0)); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("latitude 91: error %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,505 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// NREL technical report example: 17 October 2003, 12:30:30 local time, Golden, Colorado
+func example_spa_data() Spa_data {
+	return Spa_data{
+		Year:          2003,
+		Month:         10,
+		Day:           17,
+		Hour:          12,
+		Minute:        30,
+		Second:        30,
+		Timezone:      -7.0,
+		Delta_ut1:     0,
+		Delta_t:       67,
+		Longitude:     -105.1786,
+		Latitude:      39.742476,
+		Elevation:     1830.14,
+		Pressure:      820,
+		Temperature:   11,
+		Slope:         30,
+		Azm_rotation:  -10,
+		Atmos_refract: 0.5667,
+		Function:      SPA_ALL,
+	}
+}
+
+func TestSpa_calculate_time(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		t        time.Time
+		timezone float64
+	}{
+		{"report example", time.Date(2003, 10, 17, 12, 30, 30, 0, time.FixedZone("", -7*3600)), -7},
+		{"winter (MST)", time.Date(2003, 1, 17, 12, 30, 30, 0, denver), -7},
+		{"summer (MDT)", time.Date(2003, 7, 17, 12, 30, 30, 0, denver), -6},
+		{"before the switch to MDT", time.Date(2003, 4, 6, 1, 59, 59, 0, denver), -7},
+		{"after the switch to MDT", time.Date(2003, 4, 6, 3, 0, 0, 0, denver), -6},
+		{"UTC", time.Date(2003, 10, 17, 19, 30, 30, 250e6, time.UTC), 0},
+	} {
+		spa := example_spa_data()
+		if code := Spa_calculate_time(tt.t, &spa); code != 0 {
+			t.Fatalf("%s: error code %d", tt.name, code)
+		}
+
+		if spa.Timezone != tt.timezone {
+			t.Errorf("%s: Timezone %v, want %v", tt.name, spa.Timezone, tt.timezone)
+		}
+		if want := 2440587.5 + float64(tt.t.UnixNano())/86400e9; math.Abs(spa.Jd-want) > 1e-8 {
+			t.Errorf("%s: Jd %.8f, want %.8f", tt.name, spa.Jd, want)
+		}
+
+		fields := example_spa_data()
+		fields.Year, fields.Month, fields.Day = tt.t.Year(), int(tt.t.Month()), tt.t.Day()
+		fields.Hour, fields.Minute = tt.t.Hour(), tt.t.Minute()
+		fields.Second = float64(tt.t.Second()) + float64(tt.t.Nanosecond())/1e9
+		fields.Timezone = tt.timezone
+		Spa_calculate(&fields)
+		if spa.Zenith != fields.Zenith || spa.Azimuth != fields.Azimuth || spa.Sunrise != fields.Sunrise {
+			t.Errorf("%s: zenith %v, azimuth %v, sunrise %v; the date fields give %v, %v, %v", tt.name,
+				spa.Zenith, spa.Azimuth, spa.Sunrise, fields.Zenith, fields.Azimuth, fields.Sunrise)
+		}
+	}
+
+	// one second apart across the switch to daylight saving time
+	before, after := example_spa_data(), example_spa_data()
+	Spa_calculate_time(time.Date(2003, 4, 6, 1, 59, 59, 0, denver), &before)
+	Spa_calculate_time(time.Date(2003, 4, 6, 3, 0, 0, 0, denver), &after)
+	if d := (after.Jd - before.Jd) * 86400; math.Abs(d-1) > 1e-4 {
+		t.Errorf("Jd across the switch to MDT differs by %v seconds, want 1", d)
+	}
+}
+
+func TestSpa_validate(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		modify func(spa *Spa_data)
+		fields []string
+		codes  []int
+	}{
+		{"valid", func(spa *Spa_data) {}, nil, nil},
+		{"year", func(spa *Spa_data) { spa.Year = 6001 }, []string{"Year"}, []int{1}},
+		{"temperature", func(spa *Spa_data) { spa.Temperature = -273 }, []string{"Temperature"}, []int{13}},
+		{"delta ut1", func(spa *Spa_data) { spa.Delta_ut1 = 1 }, []string{"Delta_ut1"}, []int{17}},
+		{"hour 24 with minutes", func(spa *Spa_data) { spa.Hour = 24 }, []string{"Minute", "Second"}, []int{5, 6}},
+		{"several fields", func(spa *Spa_data) {
+			spa.Month, spa.Latitude, spa.Slope = 13, -91, 400
+		}, []string{"Month", "Latitude", "Slope"}, []int{2, 10, 14}},
+		{"slope unused", func(spa *Spa_data) { spa.Slope, spa.Function = 400, SPA_ZA_RTS }, nil, nil},
+	} {
+		spa := example_spa_data()
+		tt.modify(&spa)
+
+		err := Spa_validate(&spa)
+		code := Spa_calculate(&spa)
+		if tt.fields == nil {
+			if err != nil || code != 0 {
+				t.Errorf("%s: error %v, code %d, want none", tt.name, err, code)
+			}
+			continue
+		}
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: error %v does not match ErrInvalidInput", tt.name, err)
+		}
+		var validation *Spa_validation_error
+		if !errors.As(err, &validation) || len(validation.Errors) != len(tt.fields) {
+			t.Errorf("%s: error %v, want %d field errors", tt.name, err, len(tt.fields))
+			continue
+		}
+		for i, field := range validation.Errors {
+			if field.Field != tt.fields[i] || field.Code != tt.codes[i] {
+				t.Errorf("%s: field error %d is %s (code %d), want %s (code %d)", tt.name, i,
+					field.Field, field.Code, tt.fields[i], tt.codes[i])
+			}
+			if !errors.Is(field, ErrInvalidInput) {
+				t.Errorf("%s: field error %v does not match ErrInvalidInput", tt.name, field)
+			}
+		}
+		var first *Spa_range_error
+		if !errors.As(err, &first) || first != validation.Errors[0] {
+			t.Errorf("%s: errors.As gives %v, want the first field error", tt.name, first)
+		}
+		if code != tt.codes[0] {
+			t.Errorf("%s: Spa_calculate error code %d, want %d", tt.name, code, tt.codes[0])
+		}
+		if err := Spa_calculate_checked(&spa); err == nil || err.Error() != validation.Error() {
+			t.Errorf("%s: Spa_calculate_checked error %v, want %v", tt.name, err, validation)
+		}
+	}
+
+	spa := example_spa_data()
+	spa.Temperature = -300
+	want := "gosolar: Temperature = -300 is out of range, valid range: -273 (exclusive) to 6000 degrees Celsius (error code 13)"
+	if err := Spa_validate(&spa); err == nil || err.Error() != want {
+		t.Errorf("error message %q, want %q", err, want)
+	}
+
+	if err := Spa_calculate_time_checked(time.Date(7000, 1, 1, 0, 0, 0, 0, time.UTC), &spa); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Spa_calculate_time_checked in year 7000: error %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestSpa_compute(t *testing.T) {
+	in := example_spa_data()
+	in.Refraction = Spa_refraction_nrel{}
+	saved := in
+
+	r, err := Spa_compute(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in != saved {
+		t.Errorf("Spa_compute changed its input")
+	}
+
+	// intermediate values of the NREL report example, to the digits given in the report
+	for _, tt := range []struct {
+		name      string
+		got       float64
+		want      float64
+		tolerance float64
+	}{
+		{"Theta", r.Theta, 204.0182616917, 5e-11},
+		{"Beta", r.Beta, 0.0001011219, 5e-11},
+		{"Del_epsilon", r.Del_epsilon, 0.00166657, 5e-9},
+		{"Del_tau", r.Del_tau, -0.005711359, 5e-10},
+		{"Lamda", r.Lamda, 204.0085519281, 5e-11},
+		{"Nu", r.Nu, 318.5119, 5e-5},
+		{"Alpha", r.Alpha, 202.22741, 5e-6},
+		{"Delta", r.Delta, -9.31434, 5e-6},
+		{"H", r.H, 11.105902, 5e-7},
+		{"H_prime", r.H_prime, 11.10627, 5e-6},
+		{"Alpha_prime", r.Alpha_prime, 202.22704, 5e-6},
+		{"Delta_prime", r.Delta_prime, -9.316179, 5e-7},
+		{"E0", r.E0, 39.872046, 5e-7},
+		{"E", r.E, 39.888378, 5e-7},
+		{"Zenith", r.Zenith, 50.11162, 5e-6},
+		{"Azimuth", r.Azimuth, 194.34024, 5e-6},
+		{"Incidence", r.Incidence, 25.18700, 5e-6},
+	} {
+		if math.Abs(tt.got-tt.want) > tt.tolerance {
+			t.Errorf("%s = %.10f, report gives %.10f", tt.name, tt.got, tt.want)
+		}
+	}
+
+	spa := in
+	Spa_calculate(&spa)
+	if want := spa_result(&spa); r != want {
+		t.Errorf("Spa_compute result %+v, Spa_calculate gives %+v", r, want)
+	}
+	if r.Suntransit != spa.suntransit || r.Eot != spa.eot || r.Azimuth_astro != spa.azimuth_astro {
+		t.Errorf("Suntransit, Eot, Azimuth_astro = %v, %v, %v; want %v, %v, %v", r.Suntransit, r.Eot,
+			r.Azimuth_astro, spa.suntransit, spa.eot, spa.azimuth_astro)
+	}
+
+	// outputs of functions not requested stay zero
+	in.Function = SPA_ZA
+	if r, _ := Spa_compute(in); r.Incidence != 0 || r.Eot != 0 || r.Sunrise != 0 || r.Suntransit != 0 || r.Zenith == 0 {
+		t.Errorf("SPA_ZA: Incidence %v, Eot %v, Sunrise %v, Suntransit %v, Zenith %v",
+			r.Incidence, r.Eot, r.Sunrise, r.Suntransit, r.Zenith)
+	}
+
+	in.Latitude = 91
+	if r, err := Spa_compute(in); !errors.Is(err, ErrInvalidInput) || r != (Spa_result{}) {
+		t.Errorf("invalid input: result %+v, error %v", r, err)
+	}
+}
+
+func TestSpa_compute_batch(t *testing.T) {
+	in := example_spa_data()
+	times := []time.Time{
+		time.Date(2003, 10, 17, 12, 30, 30, 0, time.FixedZone("MST", -7*3600)),
+		time.Date(2003, 10, 17, 23, 0, 0, 0, time.UTC),
+		time.Date(1850, 6, 21, 4, 0, 0, 0, time.UTC),
+	}
+	observers := []Spa_observer{
+		{Longitude: -105.1786, Latitude: 39.742476, Elevation: 1830.14, Pressure: 820, Temperature: 11,
+			Slope: 30, Azm_rotation: -10, Atmos_refract: 0.5667},
+		{Longitude: 151.2, Latitude: -33.9, Pressure: 1013, Temperature: 20, Atmos_refract: 0.5667},
+		{Longitude: 25.7, Latitude: 71.2, Elevation: 300, Observer_height: 50, Pressure: 990, Temperature: -5},
+	}
+
+	b, err := Spa_compute_batch(times, observers, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tm := range times {
+		for j, obs := range observers {
+			spa := in
+			spa_set_observer(&spa, &obs)
+			r, err := Spa_compute_time(tm, spa)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			k := b.Index(i, j)
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"Jd", b.Jd[i], r.Jd},
+				{"Alpha", b.Alpha[i], r.Alpha},
+				{"Delta", b.Delta[i], r.Delta},
+				{"Nu", b.Nu[i], r.Nu},
+				{"Delta_prime", b.Delta_prime[k], r.Delta_prime},
+				{"E0", b.E0[k], r.E0},
+				{"Zenith", b.Zenith[k], r.Zenith},
+				{"Azimuth", b.Azimuth[k], r.Azimuth},
+				{"Incidence", b.Incidence[k], r.Incidence},
+			} {
+				if math.Abs(c.got-c.want) > 1e-8 {
+					t.Errorf("times[%d] observers[%d]: %s = %.10f, Spa_compute_time gives %.10f", i, j, c.name, c.got, c.want)
+				}
+			}
+		}
+	}
+
+	// every invalid row is reported
+	bad := append([]Spa_observer(nil), observers...)
+	bad[1].Latitude = 95
+	bad[2].Elevation = -7000000
+	bad_times := append([]time.Time(nil), times...)
+	bad_times[2] = time.Date(7000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err = Spa_compute_batch(bad_times, bad, in)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("invalid rows: error %v, want ErrInvalidInput", err)
+	}
+	for _, want := range []string{"times[2]: gosolar: Year", "observers[1]: gosolar: Latitude", "observers[2]: gosolar: Elevation"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "observers[0]") || strings.Contains(err.Error(), "times[0]") {
+		t.Errorf("error %q reports a valid row", err)
+	}
+	var range_err *Spa_range_error
+	if !errors.As(err, &range_err) || range_err.Field != "Year" {
+		t.Errorf("errors.As gives %v, want the Year error", range_err)
+	}
+
+	// observers are checked without any times too
+	if _, err := Spa_compute_batch(nil, bad, in); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("invalid observers without times: error %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestSpa_delta_t(t *testing.T) {
+	// Espenak & Meeus, table 1 of the Five Millennium Canon (rounded values)
+	for _, tt := range []struct {
+		year, delta_t, tolerance float64
+	}{
+		{-500, 17190, 20},
+		{0, 10580, 5},
+		{500, 5710, 1},
+		{1000, 1570, 5},
+		{1500, 200, 2},
+		{1600, 120, 1},
+		{1700, 9, 1},
+		{1800, 14, 1},
+		{1900, -3, 1},
+		{1950, 29, 1},
+		{2000.0, 63.83, 0.01},
+		{2016.5, 68.34755, 1e-9}, // halfway between the 2016 and 2017 table values
+	} {
+		if got := Spa_delta_t(tt.year); math.Abs(got-tt.delta_t) > tt.tolerance {
+			t.Errorf("Spa_delta_t(%v) = %.4f, want %v", tt.year, got, tt.delta_t)
+		}
+	}
+
+	// the segments of the fits and the table join within the documented jumps
+	for _, tt := range []struct {
+		year, jump float64
+	}{
+		{-500, 0.05}, {500, 0.1}, {1600, 0.26}, {1700, 0.17}, {1800, 0.05}, {1860, 0.06},
+		{1900, 0.09}, {1920, 0.02}, {1941, 0.001}, {1961, 0.03}, {1986, 1e-6}, {2005, 1e-6},
+		{2050, 0.002}, {2150, 1e-6}, {DELTA_T_TABLE_FIRST, 0.17},
+		{float64(DELTA_T_TABLE_FIRST + len(DELTA_T_TABLE) - 1), 1e-6},
+	} {
+		if jump := Spa_delta_t(tt.year) - Spa_delta_t(tt.year-1e-9); math.Abs(jump) > tt.jump {
+			t.Errorf("Spa_delta_t jumps by %.4f seconds at %v, want at most %v", jump, tt.year, tt.jump)
+		}
+	}
+
+	in := example_spa_data()
+	in.Delta_t, in.Delta_t_auto = 9999, true
+	r, err := Spa_compute(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Spa_delta_t(2003 + 9.5/12); r.Delta_t != want {
+		t.Errorf("Delta_t_auto: Delta_t %v, want %v", r.Delta_t, want)
+	}
+	in.Delta_t, in.Delta_t_auto = r.Delta_t, false
+	if fixed, _ := Spa_compute(in); fixed.Jde != r.Jde || fixed.Zenith != r.Zenith {
+		t.Errorf("Delta_t_auto: Jde %v, zenith %v; the same Delta_t given gives %v, %v", r.Jde, r.Zenith, fixed.Jde, fixed.Zenith)
+	}
+}
+
+// Earth orientation source recording the instant it is asked for
+type eop_stub struct {
+	dut1, tai_utc float64
+	err           error
+	asked         *time.Time
+}
+
+func (e eop_stub) DUT1(t time.Time) (float64, error) {
+	*e.asked = t
+	return e.dut1, e.err
+}
+
+func (e eop_stub) TAI_UTC(t time.Time) (float64, error) {
+	return e.tai_utc, nil
+}
+
+func TestSpa_earth_orientation(t *testing.T) {
+	var asked time.Time
+	lookup_err := errors.New("no data")
+
+	for _, tt := range []struct {
+		name    string
+		eop     eop_stub
+		code    int
+		delta_t float64
+	}{
+		{"values", eop_stub{dut1: 0.3, tai_utc: 32}, 0, TT_TAI + 32 - 0.3},
+		{"negative DUT1", eop_stub{dut1: -0.2, tai_utc: 37}, 0, TT_TAI + 37 + 0.2},
+		{"lookup error", eop_stub{err: lookup_err}, 18, 67},
+	} {
+		tt.eop.asked = &asked
+		spa := example_spa_data()
+		spa.Earth_orientation, spa.Delta_t_auto = tt.eop, true
+
+		if code := Spa_calculate(&spa); code != tt.code {
+			t.Errorf("%s: error code %d, want %d", tt.name, code, tt.code)
+		}
+		if spa.Delta_t != tt.delta_t {
+			t.Errorf("%s: Delta_t %v, want %v", tt.name, spa.Delta_t, tt.delta_t)
+		}
+		if want := time.Date(2003, 10, 17, 19, 30, 30, 0, time.UTC); !asked.Equal(want) {
+			t.Errorf("%s: looked up %v, want %v", tt.name, asked, want)
+		}
+
+		r, err := Spa_compute(spa)
+		if tt.code != 0 {
+			if !errors.Is(err, lookup_err) || !strings.Contains(err.Error(), "Delta_ut1 lookup") {
+				t.Errorf("%s: Spa_compute error %v", tt.name, err)
+			}
+			continue
+		}
+		fixed := example_spa_data()
+		fixed.Delta_ut1, fixed.Delta_t = tt.eop.dut1, tt.delta_t
+		if want, _ := Spa_compute(fixed); err != nil || r.Jd != want.Jd || r.Jde != want.Jde || r.Zenith != want.Zenith {
+			t.Errorf("%s: Jd %v, Jde %v, zenith %v, %v; the values given directly give %v, %v, %v",
+				tt.name, r.Jd, r.Jde, r.Zenith, err, want.Jd, want.Jde, want.Zenith)
+		}
+	}
+}
+
+func TestSpa_calculate_allocs(t *testing.T) {
+	for _, function := range []int{SPA_ZA, SPA_ZA_INC, SPA_ZA_RTS, SPA_ALL} {
+		spa := example_spa_data()
+		spa.Function = function
+
+		allocs := testing.AllocsPerRun(100, func() {
+			Spa_calculate(&spa)
+		})
+		if allocs != 0 {
+			t.Errorf("Spa_calculate (Function %d) allocates %v times per call, want 0", function, allocs)
+		}
+	}
+
+	in := example_spa_data()
+	allocs := testing.AllocsPerRun(100, func() {
+		Spa_compute(in)
+	})
+	if allocs != 0 {
+		t.Errorf("Spa_compute allocates %v times per call, want 0", allocs)
+	}
+}
+
+func TestSpa_calculate_concurrent(t *testing.T) {
+	want := example_spa_data()
+	Spa_calculate(&want)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				spa := example_spa_data()
+				Spa_calculate(&spa)
+				if spa != want {
+					t.Errorf("concurrent Spa_calculate result differs: %+v", spa)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkSpa_calculate(b *testing.B) {
+	for _, bm := range []struct {
+		name     string
+		function int
+	}{
+		{"ZA", SPA_ZA},
+		{"ZA_INC", SPA_ZA_INC},
+		{"ZA_RTS", SPA_ZA_RTS},
+		{"ALL", SPA_ALL},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			spa := example_spa_data()
+			spa.Function = bm.function
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Spa_calculate(&spa)
+			}
+		})
+	}
+}
+
+func BenchmarkSpa_compute(b *testing.B) {
+	in := example_spa_data()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Spa_compute(in)
+	}
+}
+
+func BenchmarkSpa_compute_time(b *testing.B) {
+	in := example_spa_data()
+	t := time.Date(2003, 10, 17, 12, 30, 30, 0, time.FixedZone("MST", -7*3600))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Spa_compute_time(t, in)
+	}
+}
+
+func BenchmarkSpa_calculate_parallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		spa := example_spa_data()
+		for pb.Next() {
+			Spa_calculate(&spa)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package gosolar
+
+import (
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Set the date, time and time zone inputs from a time.Time
+//
+// The observer's local calendar fields are taken from t in its own location and the
+// time zone is the UTC offset that location's rules give for that very instant, so
+// daylight saving transitions are handled per call instead of by a fixed Timezone.
+// Calendar is set to SPA_CALENDAR_GREGORIAN, the proleptic calendar of package time.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func spa_set_time(spa *Spa_data, t time.Time) {
+	_, offset := t.Zone()
+
+	spa.Year = t.Year()
+	spa.Month = int(t.Month())
+	spa.Day = t.Day()
+	spa.Hour = t.Hour()
+	spa.Minute = t.Minute()
+	spa.Second = float64(t.Second()) + float64(t.Nanosecond())/1e9
+	spa.Timezone = float64(offset) / 3600.0
+	spa.Calendar = SPA_CALENDAR_GREGORIAN
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Set the date, time and time zone inputs for the leap second that ends at the instant t
+//
+// time.Time has no leap seconds, so time.Date(2016, 12, 31, 23, 59, 60, 500000000, time.UTC)
+// is the instant 2017-01-01 00:00:00.5. Given such an instant, the inputs are set to second
+// 60.5 of the minute before it (23:59:60.5 UTC, or the same instant in t's local time).
+///////////////////////////////////////////////////////////////////////////////////////////////
+func spa_set_time_leap(spa *Spa_data, t time.Time) {
+	spa_set_time(spa, t.Add(-time.Second))
+	spa.Second++
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters for the instant t and put into structure
+// Note: The observer inputs (Longitude, Latitude, Elevation, Pressure, Temperature, Delta_ut1,
+// Delta_t, Slope, Azm_rotation, Atmos_refract and Function) must already be in structure.
+// Year, Month, Day, Hour, Minute, Second, Timezone and Calendar are set from t, and the
+// output times (Sunrise, Sunset) are local to t's location on t's calendar day.
+// A time.Time cannot hold second 60, so a leap second given to time.Date (23:59:60.5) is
+// folded into the next minute (00:00:00.5) and calculated one second late; use
+// Spa_calculate_time_leap for it, or set Second to 60 to 61 in structure.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_calculate_time(t time.Time, spa *Spa_data) int {
+	spa_set_time(spa, t)
+
+	return Spa_calculate(spa)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate all SPA parameters for the leap second that ends at the instant t
+// Note: Same as Spa_calculate_time with t taken as a reading of the leap second (e.g. a
+// logged 23:59:60.5 UTC that became 00:00:00.5 in a time.Time); the leap second table of the
+// structure must have a leap second there, else the result is error code 6
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_calculate_time_leap(t time.Time, spa *Spa_data) int {
+	spa_set_time_leap(spa, t)
+
+	return Spa_calculate(spa)
+}
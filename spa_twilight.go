@@ -0,0 +1,52 @@
+package gosolar
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SPA_TWILIGHT_CIVIL        = iota //sun center 6 degrees below the horizon
+	SPA_TWILIGHT_NAUTICAL            //sun center 12 degrees below the horizon
+	SPA_TWILIGHT_ASTRONOMICAL        //sun center 18 degrees below the horizon
+	SPA_TWILIGHT_COUNT
+)
+
+// depression of the sun center below the geometric horizon for each twilight kind [degrees]
+var twilight_depression = [SPA_TWILIGHT_COUNT]float64{6, 12, 18}
+
+// Spa_twilight holds the begin (dawn) and end (dusk) of a twilight on one local calendar day.
+type Spa_twilight struct {
+	State int       // SPA_RTS_NORMAL, SPA_RTS_POLAR_DAY (never this dark) or SPA_RTS_POLAR_NIGHT (never this light)
+	Begin time.Time // morning twilight begins (dawn), zero if it does not happen that day
+	End   time.Time // evening twilight ends (dusk), zero if it does not happen that day
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate dawn and dusk for a depression angle of the sun center below the horizon
+// Note: depression is in degrees (6 civil, 12 nautical, 18 astronomical), 0 to 90
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_sun_twilight_depression(t time.Time, in Spa_data, depression float64) (Spa_twilight, error) {
+	if depression < 0 || depression > 90 {
+		return Spa_twilight{}, &Spa_validation_error{Errors: []*Spa_range_error{{Field: "depression",
+			Value: depression, Min: 0, Max: 90, Range: "0 to 90 degrees"}}}
+	}
+
+	rts, err := Spa_sun_altitude_events(t, in, -depression)
+	if err != nil {
+		return Spa_twilight{}, err
+	}
+
+	return Spa_twilight{State: rts.State, Begin: rts.Sunrise, End: rts.Sunset}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Calculate civil, nautical or astronomical dawn and dusk of the local calendar day of t
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Spa_sun_twilight(t time.Time, in Spa_data, kind int) (Spa_twilight, error) {
+	if kind < 0 || kind >= SPA_TWILIGHT_COUNT {
+		return Spa_twilight{}, fmt.Errorf("%w: unknown twilight kind %d", ErrInvalidInput, kind)
+	}
+
+	return Spa_sun_twilight_depression(t, in, twilight_depression[kind])
+}
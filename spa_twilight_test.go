@@ -0,0 +1,92 @@
+package gosolar
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpa_sun_twilight(t *testing.T) {
+	reykjavik := load_location(t, "Atlantic/Reykjavik")
+	longyearbyen := load_location(t, "Arctic/Longyearbyen")
+
+	iceland := rts_site(64.1466, -21.9426)
+	svalbard := rts_site(78.2232, 15.6267)
+
+	// Longyearbyen has no civil twilight from about November 14 to January 29, and it does not
+	// get nautically dark from late March to late September
+	tests := []struct {
+		name  string
+		in    Spa_data
+		day   time.Time
+		kind  int
+		state int
+	}{
+		{"Reykjavik winter civil", iceland, time.Date(2023, 12, 21, 12, 0, 0, 0, reykjavik), SPA_TWILIGHT_CIVIL, SPA_RTS_NORMAL},
+		{"Reykjavik bright night", iceland, time.Date(2023, 6, 21, 12, 0, 0, 0, reykjavik), SPA_TWILIGHT_CIVIL, SPA_RTS_POLAR_DAY},
+		{"Reykjavik bright night, nautical", iceland, time.Date(2023, 6, 21, 12, 0, 0, 0, reykjavik), SPA_TWILIGHT_NAUTICAL, SPA_RTS_POLAR_DAY},
+		{"Longyearbyen civil polar night", svalbard, time.Date(2023, 12, 21, 12, 0, 0, 0, longyearbyen), SPA_TWILIGHT_CIVIL, SPA_RTS_POLAR_NIGHT},
+		{"Longyearbyen nautical at midwinter", svalbard, time.Date(2023, 12, 21, 12, 0, 0, 0, longyearbyen), SPA_TWILIGHT_NAUTICAL, SPA_RTS_NORMAL},
+		{"Longyearbyen astronomical at midwinter", svalbard, time.Date(2023, 12, 21, 12, 0, 0, 0, longyearbyen), SPA_TWILIGHT_ASTRONOMICAL, SPA_RTS_NORMAL},
+		{"Longyearbyen civil in February", svalbard, time.Date(2024, 2, 10, 12, 0, 0, 0, longyearbyen), SPA_TWILIGHT_CIVIL, SPA_RTS_NORMAL},
+		{"Longyearbyen nautical in April", svalbard, time.Date(2024, 4, 15, 12, 0, 0, 0, longyearbyen), SPA_TWILIGHT_NAUTICAL, SPA_RTS_POLAR_DAY},
+		{"Longyearbyen astronomical in October", svalbard, time.Date(2023, 10, 20, 12, 0, 0, 0, longyearbyen), SPA_TWILIGHT_ASTRONOMICAL, SPA_RTS_NORMAL},
+	}
+
+	for _, tt := range tests {
+		tw, err := Spa_sun_twilight(tt.day, tt.in, tt.kind)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tw.State != tt.state {
+			t.Errorf("%s: state %d, want %d", tt.name, tw.State, tt.state)
+		}
+		if (tt.state == SPA_RTS_NORMAL) == (tw.Begin.IsZero() && tw.End.IsZero()) {
+			t.Errorf("%s: begin %v, end %v in state %d", tt.name, tw.Begin, tw.End, tw.State)
+		}
+
+		for _, e := range []time.Time{tw.Begin, tw.End} {
+			if e.IsZero() {
+				continue
+			}
+
+			var spa Spa_data
+			if err := spa_sun_position_at(e, &tt.in, &spa); err != nil {
+				t.Fatal(err)
+			}
+			h := rts_sun_altitude(spa.Latitude, spa.delta, spa.H)
+			if want := -twilight_depression[tt.kind]; math.Abs(h-want) > 0.005 {
+				t.Errorf("%s: sun altitude %v at %v, want %v", tt.name, h, e, want)
+			}
+		}
+	}
+
+	// dawn gets lighter and dusk darker, one twilight inside the other
+	day := time.Date(2023, 12, 21, 12, 0, 0, 0, reykjavik)
+	rts, err := Spa_sun_rts(day, iceland)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin, end := rts.Sunrise, rts.Sunset
+	for _, kind := range []int{SPA_TWILIGHT_CIVIL, SPA_TWILIGHT_NAUTICAL, SPA_TWILIGHT_ASTRONOMICAL} {
+		tw, err := Spa_sun_twilight(day, iceland, kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tw.Begin.Before(begin) || !tw.End.After(end) {
+			t.Errorf("twilight %d from %v to %v is not outside %v to %v", kind, tw.Begin, tw.End, begin, end)
+		}
+		begin, end = tw.Begin, tw.End
+	}
+
+	if _, err := Spa_sun_twilight(day, iceland, SPA_TWILIGHT_COUNT); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown kind: error %v, want ErrInvalidInput", err)
+	}
+	var validation *Spa_validation_error
+	for _, depression := range []float64{-1, 91} {
+		if _, err := Spa_sun_twilight_depression(day, iceland, depression); !errors.As(err, &validation) {
+			t.Errorf("depression %v: error %v, want a validation error", depression, err)
+		}
+	}
+}
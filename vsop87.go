@@ -0,0 +1,163 @@
+package gosolar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	VSOP87_VARIABLE_L = iota //heliocentric ecliptic longitude
+	VSOP87_VARIABLE_B        //heliocentric ecliptic latitude
+	VSOP87_VARIABLE_R        //radius vector
+	VSOP87_VARIABLE_COUNT
+)
+
+const VSOP87_POWER_COUNT = 6 //series are polynomials of jme up to the fifth power
+
+// Vsop87_series holds the VSOP87D periodic terms of one planet (heliocentric spherical
+// coordinates, ecliptic and equinox of the date). Each variable is indexed [power][term] and
+// every term is {A, B, C} as in L_TERMS: A in 1e-8 radians (L, B) or 1e-8 AU (R), B in
+// radians and C in radians per Julian millennium.
+type Vsop87_series struct {
+	Name string        // planet name as in the VSOP87 file header (e.g. "VENUS")
+	L    [][][]float64 // longitude terms
+	B    [][][]float64 // latitude terms
+	R    [][][]float64 // radius vector terms
+}
+
+// Vsop87_earth is the Earth series SPA uses (the truncated terms of the NREL SPA report).
+var Vsop87_earth = Vsop87_series{Name: "EARTH", L: L_TERMS, B: B_TERMS, R: R_TERMS}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Sum a VSOP87 variable: the periodic terms of each power, then the polynomial in jme
+///////////////////////////////////////////////////////////////////////////////////////////////
+func vsop87_value(terms [][][]float64, jme float64) float64 {
+	var sum [VSOP87_POWER_COUNT]float64
+	var i int
+
+	for i = 0; i < len(terms); i++ {
+		sum[i] = earth_periodic_term_summation(terms[i], len(terms[i]), jme)
+	}
+
+	return earth_values(sum[:len(terms)], len(terms), jme)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Heliocentric longitude, latitude [degrees] and radius vector [AU] of the series at jme
+///////////////////////////////////////////////////////////////////////////////////////////////
+func (s *Vsop87_series) heliocentric(jme float64, l, b, r *float64) {
+	*l = limit_degrees(rad2deg(vsop87_value(s.L, jme)))
+	*b = rad2deg(vsop87_value(s.B, jme))
+	*r = vsop87_value(s.R, jme)
+}
+
+// series of variable v (VSOP87_VARIABLE_*)
+func (s *Vsop87_series) variable(v int) *[][][]float64 {
+	switch v {
+	case VSOP87_VARIABLE_L:
+		return &s.L
+	case VSOP87_VARIABLE_B:
+		return &s.B
+	}
+
+	return &s.R
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+// Parse a VSOP87D planet file (VSOP87D.mer ... VSOP87D.nep of the original distribution)
+//
+// Every block starts with a header line such as
+//
+//	VSOP87 VERSION D1    MERCURY   VARIABLE 1 (LBR)       *T**0    1449 TERMS    ...
+//
+// followed by its terms, whose last three fields are A, B and C. Only version D (spherical
+// coordinates, equinox of the date) is accepted, as the positions are used with SPA's
+// nutation and aberration of the date.
+///////////////////////////////////////////////////////////////////////////////////////////////
+func Parse_vsop87(r io.Reader) (*Vsop87_series, error) {
+	s := &Vsop87_series{}
+	var terms *[][][]float64
+	var power, remaining int
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line_no++
+
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] == "VSOP87" {
+			if remaining > 0 {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: %d terms missing in the previous block", line_no, remaining)
+			}
+			if len(fields) < 10 || fields[1] != "VERSION" || fields[4] != "VARIABLE" || fields[9] != "TERMS" {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: malformed header", line_no)
+			}
+			if !strings.HasPrefix(fields[2], "D") {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: version %s is not supported, need VSOP87D", line_no, fields[2])
+			}
+			if s.Name == "" {
+				s.Name = fields[3]
+			} else if s.Name != fields[3] {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: planet %s in a file of %s", line_no, fields[3], s.Name)
+			}
+
+			v, err := strconv.Atoi(fields[5])
+			if err != nil || v < 1 || v > VSOP87_VARIABLE_COUNT {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: bad variable %s", line_no, fields[5])
+			}
+			power, err = strconv.Atoi(strings.TrimPrefix(fields[7], "*T**"))
+			if err != nil || power < 0 || power >= VSOP87_POWER_COUNT {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: bad power %s", line_no, fields[7])
+			}
+			remaining, err = strconv.Atoi(fields[8])
+			if err != nil || remaining < 0 {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: bad term count %s", line_no, fields[8])
+			}
+
+			terms = s.variable(v - 1)
+			for len(*terms) <= power {
+				*terms = append(*terms, nil)
+			}
+			continue
+		}
+
+		if terms == nil || remaining == 0 {
+			return nil, fmt.Errorf("gosolar: VSOP87 line %d: term outside of a block", line_no)
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("gosolar: VSOP87 line %d: expected A, B and C", line_no)
+		}
+
+		term := make([]float64, TERM_COUNT)
+		for i := 0; i < TERM_COUNT; i++ {
+			v, err := strconv.ParseFloat(fields[len(fields)-TERM_COUNT+i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("gosolar: VSOP87 line %d: %v", line_no, err)
+			}
+			term[i] = v
+		}
+		term[TERM_A] *= 1e8
+
+		(*terms)[power] = append((*terms)[power], term)
+		remaining--
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("gosolar: VSOP87 data ends with %d terms missing", remaining)
+	}
+	if len(s.L) == 0 || len(s.B) == 0 || len(s.R) == 0 {
+		return nil, fmt.Errorf("gosolar: VSOP87 data lacks the L, B or R series")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,96 @@
+package gosolar
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func open_vsop87(t *testing.T, name string) *Vsop87_series {
+	t.Helper()
+
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s, err := Parse_vsop87(f)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	return s
+}
+
+func TestParse_vsop87(t *testing.T) {
+	// the Venus terms of Meeus' Appendix III in the VSOP87D file layout: the term lines carry
+	// the 12 multipliers and S, K before A (in radians or AU), B and C
+	s := open_vsop87(t, "vsop87d_venus_meeus.txt")
+
+	if s.Name != "VENUS" {
+		t.Errorf("name %q, want VENUS", s.Name)
+	}
+	for _, tt := range []struct {
+		variable string
+		terms    [][][]float64
+		counts   []int
+	}{
+		{"L", s.L, []int{24, 12, 8, 3, 3, 1}},
+		{"B", s.B, []int{9, 4, 4, 4, 1}},
+		{"R", s.R, []int{12, 3, 3, 1, 1}},
+	} {
+		if len(tt.terms) != len(tt.counts) {
+			t.Errorf("%s: %d powers, want %d", tt.variable, len(tt.terms), len(tt.counts))
+			continue
+		}
+		for i, n := range tt.counts {
+			if len(tt.terms[i]) != n {
+				t.Errorf("%s%d: %d terms, want %d", tt.variable, i, len(tt.terms[i]), n)
+			}
+		}
+	}
+
+	// A is scaled to 1e-8 radians or AU like L_TERMS, B and C are taken as they are
+	for _, tt := range []struct {
+		name string
+		got  []float64
+		want []float64
+	}{
+		{"L0 term 1", s.L[0][0], []float64{317614667, 0, 0}},
+		{"L0 term 2", s.L[0][1], []float64{1353968, 5.5931332, 10213.2855462}},
+		{"L1 term 1", s.L[1][0], []float64{1021352943053, 0, 0}},
+		{"B0 term 3", s.B[0][2], []float64{32815, 3.14159, 0}},
+		{"R4 term 1", s.R[4][0], []float64{1, 0.92, 10213.29}},
+	} {
+		for i := range tt.want {
+			if math.Abs(tt.got[i]-tt.want[i]) > 1e-9*math.Max(1, math.Abs(tt.want[i])) {
+				t.Errorf("%s: %v, want %v", tt.name, tt.got, tt.want)
+				break
+			}
+		}
+	}
+
+	header := " VSOP87 VERSION D2    VENUS     VARIABLE 1 (LBR)       *T**0       1 TERMS    TEST\n"
+	term := " 4210    1  0  0  0  0  0  0  0  0  0  0  0  0   0.00000000000     3.17614667000     3.17614667000 0.00000000000       0.00000000000\n"
+	for _, bad := range []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"no B and R", header + term},
+		{"version A", strings.Replace(header, "D2", "A2", 1) + term},
+		{"missing term", header},
+		{"term outside a block", term},
+		{"two planets", header + term + strings.Replace(header, "VENUS    ", "MARS     ", 1) + term},
+		{"variable 4", strings.Replace(header, "VARIABLE 1", "VARIABLE 4", 1) + term},
+		{"power 6", strings.Replace(header, "*T**0", "*T**6", 1) + term},
+		{"short term", header + " 4210 1.0 2.0\n"},
+		{"malformed header", " VSOP87 VERSION D2 VENUS\n"},
+	} {
+		if _, err := Parse_vsop87(strings.NewReader(bad.data)); err == nil {
+			t.Errorf("%s: no error", bad.name)
+		}
+	}
+}